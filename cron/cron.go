@@ -2,6 +2,7 @@ package cron
 
 import (
 	"backend/db"
+	"backend/src"
 	"log"
 
 	"github.com/robfig/cron/v3"
@@ -23,5 +24,27 @@ func Run() {
 		}
 		log.Println("cron active")
 	})
+	//accept requests still pending on accounts that went public
+	cron.AddFunc("*/15 * * * *", func() {
+		accepted, err := src.AcceptStrandedRequests()
+		if err != nil {
+			log.Println("Error accepting pending follow requests", err)
+			return
+		}
+		if accepted > 0 {
+			log.Println("accepted pending follow requests:", accepted)
+		}
+	})
+	//precompute follow suggestions of changed users every hour
+	cron.AddFunc("0 * * * *", func() {
+		computed, err := src.ComputeAllSuggestions()
+		if err != nil {
+			log.Println("Error computing follow suggestions", err)
+			return
+		}
+		if computed > 0 {
+			log.Println("computed follow suggestions of users:", computed)
+		}
+	})
 	cron.Start()
 }
@@ -0,0 +1,16 @@
+-- story highlights pinned to a user's profile
+CREATE TABLE IF NOT EXISTS highlights (
+	highlight_id BIGSERIAL PRIMARY KEY,
+	user_id BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+	title VARCHAR(15) NOT NULL,
+	cover_path TEXT NOT NULL DEFAULT '',
+	created_on TIMESTAMP NOT NULL DEFAULT current_timestamp
+);
+
+-- ordered stories of a highlight
+CREATE TABLE IF NOT EXISTS highlight_stories (
+	highlight_id BIGINT NOT NULL REFERENCES highlights(highlight_id) ON DELETE CASCADE,
+	story_id BIGINT NOT NULL REFERENCES stories(story_id) ON DELETE CASCADE,
+	position INT NOT NULL,
+	PRIMARY KEY (highlight_id, story_id)
+);
@@ -0,0 +1,8 @@
+-- record when each viewer saw a story
+ALTER TABLE story_seen_status ADD COLUMN IF NOT EXISTS seen_on TIMESTAMP NOT NULL DEFAULT current_timestamp;
+
+-- keep a single view per viewer and story
+DELETE FROM story_seen_status a USING story_seen_status b
+	WHERE a.ctid < b.ctid AND a.user_id=b.user_id AND a.story_id=b.story_id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS story_seen_status_viewer ON story_seen_status(story_id, user_id);
@@ -0,0 +1,2 @@
+-- stories that reshare a post are removed together with the post
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS post_id BIGINT REFERENCES posts(post_id) ON DELETE CASCADE;
@@ -0,0 +1,10 @@
+-- close friends list of a user
+CREATE TABLE IF NOT EXISTS close_friends (
+	user_id BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+	friend_id BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+	added_on TIMESTAMP NOT NULL DEFAULT current_timestamp,
+	PRIMARY KEY (user_id, friend_id)
+);
+
+-- audience of a story, everyone or close_friends
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS audience VARCHAR(16) NOT NULL DEFAULT 'everyone';
@@ -0,0 +1,17 @@
+-- emoji reactions to a story, one per viewer
+CREATE TABLE IF NOT EXISTS story_reactions (
+	story_id BIGINT NOT NULL REFERENCES stories(story_id) ON DELETE CASCADE,
+	user_id BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+	emoji VARCHAR(16) NOT NULL,
+	reacted_on TIMESTAMP NOT NULL DEFAULT current_timestamp,
+	PRIMARY KEY (story_id, user_id)
+);
+
+-- private text replies to the story author
+CREATE TABLE IF NOT EXISTS story_replies (
+	reply_id BIGSERIAL PRIMARY KEY,
+	story_id BIGINT NOT NULL REFERENCES stories(story_id) ON DELETE CASCADE,
+	user_id BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+	reply_body VARCHAR(1000) NOT NULL,
+	replied_on TIMESTAMP NOT NULL DEFAULT current_timestamp
+);
@@ -0,0 +1,21 @@
+-- interactive stickers placed on a story
+CREATE TABLE IF NOT EXISTS story_stickers (
+	sticker_id BIGSERIAL PRIMARY KEY,
+	story_id BIGINT NOT NULL REFERENCES stories(story_id) ON DELETE CASCADE,
+	sticker_type VARCHAR(16) NOT NULL,
+	prompt VARCHAR(100) NOT NULL DEFAULT '',
+	options TEXT[] NOT NULL DEFAULT '{}',
+	emoji VARCHAR(16) NOT NULL DEFAULT '',
+	created_on TIMESTAMP NOT NULL DEFAULT current_timestamp
+);
+
+-- votes, answers and slider values, one per viewer and sticker
+CREATE TABLE IF NOT EXISTS sticker_responses (
+	sticker_id BIGINT NOT NULL REFERENCES story_stickers(sticker_id) ON DELETE CASCADE,
+	user_id BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+	option_index INT,
+	answer VARCHAR(300),
+	slider_value REAL,
+	responded_on TIMESTAMP NOT NULL DEFAULT current_timestamp,
+	PRIMARY KEY (sticker_id, user_id)
+);
@@ -0,0 +1,18 @@
+-- position of a tag on the story, x and y are normalized to (0,1)
+ALTER TABLE story_tags ADD COLUMN IF NOT EXISTS x REAL NOT NULL DEFAULT 0.5;
+ALTER TABLE story_tags ADD COLUMN IF NOT EXISTS y REAL NOT NULL DEFAULT 0.5;
+ALTER TABLE story_tags ADD COLUMN IF NOT EXISTS rotation REAL NOT NULL DEFAULT 0;
+
+-- story reshared by a user mentioned in it
+ALTER TABLE stories ADD COLUMN IF NOT EXISTS reshared_from BIGINT REFERENCES stories(story_id) ON DELETE CASCADE;
+
+-- notifications delivered to a user
+CREATE TABLE IF NOT EXISTS notifications (
+	notification_id BIGSERIAL PRIMARY KEY,
+	user_id BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+	actor_id BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+	notification_type VARCHAR(32) NOT NULL,
+	story_id BIGINT REFERENCES stories(story_id) ON DELETE CASCADE,
+	seen BOOLEAN NOT NULL DEFAULT false,
+	created_on TIMESTAMP NOT NULL DEFAULT current_timestamp
+);
@@ -0,0 +1,4 @@
+-- replies to a top level comment
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS parent_comment_id BIGINT REFERENCES comments(comment_id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS comments_parent_comment_id ON comments(parent_comment_id);
@@ -0,0 +1,7 @@
+-- likes on comments
+CREATE TABLE IF NOT EXISTS comment_likes (
+	comment_id BIGINT NOT NULL REFERENCES comments(comment_id) ON DELETE CASCADE,
+	user_id BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+	liked_on TIMESTAMP NOT NULL DEFAULT current_timestamp,
+	PRIMARY KEY (comment_id, user_id)
+);
@@ -0,0 +1,5 @@
+-- who can comment on a post, and the account default for new posts
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS comment_audience VARCHAR(16) NOT NULL DEFAULT 'everyone';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS comment_audience VARCHAR(16) NOT NULL DEFAULT 'everyone';
+
+UPDATE posts SET comment_audience='off' WHERE hide_comments=true;
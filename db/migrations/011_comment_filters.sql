@@ -0,0 +1,9 @@
+-- comment filter of a user applied to comments on their posts
+CREATE TABLE IF NOT EXISTS comment_filters (
+	user_id BIGINT PRIMARY KEY REFERENCES users(user_id) ON DELETE CASCADE,
+	hide_offensive BOOLEAN NOT NULL DEFAULT true,
+	keywords TEXT[] NOT NULL DEFAULT '{}'
+);
+
+-- comments waiting for review by the post owner
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS hidden BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,12 @@
+-- last time a comment was edited, null for comments never edited
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS edited_on TIMESTAMP;
+
+-- previous bodies of edited comments
+CREATE TABLE IF NOT EXISTS comment_edits (
+	edit_id BIGSERIAL PRIMARY KEY,
+	comment_id BIGINT NOT NULL REFERENCES comments(comment_id) ON DELETE CASCADE,
+	comment_body TEXT NOT NULL,
+	edited_on TIMESTAMP NOT NULL DEFAULT current_timestamp
+);
+
+CREATE INDEX IF NOT EXISTS comment_edits_comment_id_idx ON comment_edits(comment_id);
@@ -0,0 +1,8 @@
+-- comments pinned by the post owner, unpinned when the comment is deleted
+CREATE TABLE IF NOT EXISTS pinned_comments (
+	comment_id BIGINT PRIMARY KEY REFERENCES comments(comment_id) ON DELETE CASCADE,
+	post_id BIGINT NOT NULL REFERENCES posts(post_id) ON DELETE CASCADE,
+	pinned_on TIMESTAMP NOT NULL DEFAULT current_timestamp
+);
+
+CREATE INDEX IF NOT EXISTS pinned_comments_post_id_idx ON pinned_comments(post_id);
@@ -0,0 +1,15 @@
+-- likes are keyed on user_id so renaming an account keeps its likes
+ALTER TABLE likes ADD COLUMN IF NOT EXISTS user_id BIGINT REFERENCES users(user_id) ON DELETE CASCADE;
+ALTER TABLE likes ADD COLUMN IF NOT EXISTS liked_on TIMESTAMP NOT NULL DEFAULT current_timestamp;
+
+UPDATE likes l SET user_id=u.user_id FROM users u WHERE l.user_id IS NULL AND u.user_name=l.user_name;
+
+-- likes left by renamed accounts can no longer be matched
+DELETE FROM likes WHERE user_id IS NULL;
+
+-- keep one like per user on a post
+DELETE FROM likes a USING likes b WHERE a.ctid < b.ctid AND a.post_id=b.post_id AND a.user_id=b.user_id;
+
+ALTER TABLE likes ALTER COLUMN user_id SET NOT NULL;
+ALTER TABLE likes DROP COLUMN IF EXISTS user_name;
+ALTER TABLE likes ADD CONSTRAINT likes_post_user_key UNIQUE (post_id, user_id);
@@ -0,0 +1,4 @@
+-- a like is now one of the post reactions, existing likes are hearts
+ALTER TABLE likes ADD COLUMN IF NOT EXISTS reaction TEXT NOT NULL DEFAULT '❤️';
+
+CREATE INDEX IF NOT EXISTS likes_post_reaction_idx ON likes(post_id, reaction);
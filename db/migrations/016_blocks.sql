@@ -0,0 +1,9 @@
+-- users blocked by a user, a block hides both accounts from each other
+CREATE TABLE IF NOT EXISTS blocks (
+	user_id BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+	blocked_id BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+	blocked_on TIMESTAMP NOT NULL DEFAULT current_timestamp,
+	PRIMARY KEY (user_id, blocked_id)
+);
+
+CREATE INDEX IF NOT EXISTS blocks_blocked_id_idx ON blocks(blocked_id);
@@ -0,0 +1,9 @@
+-- accounts muted by a user, the follow is kept and the muted user is not told
+CREATE TABLE IF NOT EXISTS mutes (
+	user_id BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+	muted_id BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+	mute_posts BOOLEAN NOT NULL DEFAULT false,
+	mute_stories BOOLEAN NOT NULL DEFAULT false,
+	muted_on TIMESTAMP NOT NULL DEFAULT current_timestamp,
+	PRIMARY KEY (user_id, muted_id)
+);
@@ -0,0 +1,11 @@
+-- accounts restricted by a user
+CREATE TABLE IF NOT EXISTS restricts (
+	user_id BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+	restricted_id BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+	restricted_on TIMESTAMP NOT NULL DEFAULT current_timestamp,
+	PRIMARY KEY (user_id, restricted_id)
+);
+
+-- tags by a restricted account wait for approval of the tagged user
+ALTER TABLE tagged_users ADD COLUMN IF NOT EXISTS approved BOOLEAN NOT NULL DEFAULT true;
+ALTER TABLE story_tags ADD COLUMN IF NOT EXISTS approved BOOLEAN NOT NULL DEFAULT true;
@@ -0,0 +1,25 @@
+-- precomputed follow suggestions of a user
+CREATE TABLE IF NOT EXISTS follow_suggestions (
+	user_id BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+	suggested_id BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+	mutual_count INT NOT NULL DEFAULT 0,
+	follows_you BOOLEAN NOT NULL DEFAULT false,
+	tagged_together INT NOT NULL DEFAULT 0,
+	score INT NOT NULL DEFAULT 0,
+	computed_on TIMESTAMP NOT NULL DEFAULT current_timestamp,
+	PRIMARY KEY (user_id, suggested_id)
+);
+
+-- suggestions a user dismissed, never suggested again
+CREATE TABLE IF NOT EXISTS dismissed_suggestions (
+	user_id BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+	suggested_id BIGINT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+	dismissed_on TIMESTAMP NOT NULL DEFAULT current_timestamp,
+	PRIMARY KEY (user_id, suggested_id)
+);
+
+-- when suggestions of a user were last computed, kept even when none were found
+CREATE TABLE IF NOT EXISTS suggestions_computed (
+	user_id BIGINT PRIMARY KEY REFERENCES users(user_id) ON DELETE CASCADE,
+	computed_on TIMESTAMP NOT NULL DEFAULT current_timestamp
+);
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"backend/db"
+	"backend/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+func BlockUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var block models.Block
+	err := json.NewDecoder(r.Body).Decode(&block)
+	if err != nil {
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if block.UserID <= 0 || block.BlockedId <= 0 || block.UserID == block.BlockedId {
+		http.Error(w, "Invalid ids or missing fields", http.StatusBadRequest)
+		return
+	}
+
+	var idexists bool
+	err = db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE user_id=$1)", block.BlockedId).Scan(&idexists)
+	if err != nil {
+		http.Error(w, "Invalid user-id", http.StatusInternalServerError)
+		return
+	}
+	if !idexists {
+		http.Error(w, "No user exists with this user-id", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "Error blocking user", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("INSERT INTO blocks(user_id,blocked_id) VALUES($1,$2) ON CONFLICT DO NOTHING", block.UserID, block.BlockedId)
+	if err != nil {
+		http.Error(w, "Error blocking user", http.StatusInternalServerError)
+		return
+	}
+
+	//follows and pending requests are removed both ways
+	_, err = tx.Exec("DELETE FROM follower WHERE (user_id=$1 AND follower_id=$2) OR (user_id=$2 AND follower_id=$1)", block.UserID, block.BlockedId)
+	if err != nil {
+		http.Error(w, "Error removing follows", http.StatusInternalServerError)
+		return
+	}
+
+	_, err = tx.Exec("DELETE FROM close_friends WHERE (user_id=$1 AND friend_id=$2) OR (user_id=$2 AND friend_id=$1)", block.UserID, block.BlockedId)
+	if err != nil {
+		http.Error(w, "Error removing close friends", http.StatusInternalServerError)
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		http.Error(w, "Error blocking user", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintln(w, "User blocked")
+}
+
+func UnblockUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var block models.Block
+	err := json.NewDecoder(r.Body).Decode(&block)
+	if err != nil {
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if block.UserID <= 0 || block.BlockedId <= 0 {
+		http.Error(w, "Invalid ids or missing fields", http.StatusBadRequest)
+		return
+	}
+
+	_, err = db.DB.Exec("DELETE FROM blocks WHERE user_id=$1 AND blocked_id=$2", block.UserID, block.BlockedId)
+	if err != nil {
+		http.Error(w, "Error unblocking user", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintln(w, "User unblocked")
+}
+
+func BlockedUsers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var userId models.UserID
+	err := json.NewDecoder(r.Body).Decode(&userId)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if userId.UserId <= 0 {
+		http.Error(w, "Invalid user id or missing field", http.StatusBadRequest)
+		return
+	}
+
+	row, err := db.DB.Query(`SELECT u.user_id,u.user_name,u.name,u.display_pic FROM blocks b
+		JOIN users u ON u.user_id=b.blocked_id
+		WHERE b.user_id=$1 ORDER BY b.blocked_on DESC`, userId.UserId)
+	if err != nil {
+		http.Error(w, "Error retrieving blocked users", http.StatusInternalServerError)
+		return
+	}
+	defer row.Close()
+
+	var blocked []models.Accounts
+	for row.Next() {
+		var acc models.Accounts
+		err = row.Scan(&acc.UserID, &acc.UserName, &acc.Name, &acc.ProfilePic)
+		if err != nil {
+			http.Error(w, "Error reading blocked users", http.StatusInternalServerError)
+			return
+		}
+		acc.ProfilePic = "http://localhost:3000/getProfilePic/" + acc.ProfilePic
+		blocked = append(blocked, acc)
+	}
+
+	json.NewEncoder(w).Encode(blocked)
+}
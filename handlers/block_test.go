@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"backend/models"
+	"database/sql/driver"
+	"net/http"
+	"testing"
+)
+
+func TestBlockUserRemovesFollowsBothWays(t *testing.T) {
+	expectQueries(t,
+		fakeQuery{sql: "SELECT EXISTS(SELECT 1 FROM users WHERE user_id=$1)", columns: []string{"exists"}, rows: [][]driver.Value{{true}}},
+		fakeQuery{sql: "INSERT INTO blocks(user_id,blocked_id)", args: []driver.Value{int64(2), int64(7)}, affected: 1},
+		fakeQuery{sql: "DELETE FROM follower WHERE (user_id=$1 AND follower_id=$2) OR (user_id=$2 AND follower_id=$1)", args: []driver.Value{int64(2), int64(7)}},
+		fakeQuery{sql: "DELETE FROM close_friends", args: []driver.Value{int64(2), int64(7)}},
+	)
+
+	w := serve(BlockUser, http.MethodPost, models.Block{UserID: 2, BlockedId: 7})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body)
+	}
+}
+
+func TestBlockUserRejectsSelf(t *testing.T) {
+	expectQueries(t)
+
+	w := serve(BlockUser, http.MethodPost, models.Block{UserID: 2, BlockedId: 2})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d, got %d: %s", http.StatusBadRequest, w.Code, w.Body)
+	}
+}
+
+func TestCanViewStoryHidesBlockedOwner(t *testing.T) {
+	expectQueries(t,
+		fakeQuery{sql: "SELECT user_id,success,audience FROM stories", columns: []string{"user_id", "success", "audience"},
+			rows: [][]driver.Value{{int64(2), true, models.StoryAudienceEveryone}}},
+		fakeQuery{sql: "FROM blocks", args: []driver.Value{int64(7), int64(2)}, columns: []string{"blocked"}, rows: [][]driver.Value{{true}}},
+	)
+
+	visible, ownerId, err := canViewStory(7, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if visible || ownerId != 2 {
+		t.Errorf("expected story of 2 to be hidden, got visible=%v owner=%d", visible, ownerId)
+	}
+}
+
+func TestSharePostToStoryRejectsBlockedAuthor(t *testing.T) {
+	expectQueries(t,
+		fakeQuery{sql: "SELECT user_id,post_path FROM posts", columns: []string{"user_id", "post_path"}, rows: [][]driver.Value{{int64(2), "posts/1.jpg"}}},
+		fakeQuery{sql: "FROM blocks", args: []driver.Value{int64(7), int64(2)}, columns: []string{"blocked"}, rows: [][]driver.Value{{true}}},
+	)
+
+	w := serve(SharePostToStory, http.MethodPost, models.PostAsStory{UserID: 7, PostId: 1})
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected %d, got %d: %s", http.StatusNotFound, w.Code, w.Body)
+	}
+}
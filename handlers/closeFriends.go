@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"backend/db"
+	"backend/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+func AddCloseFriend(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var friend models.CloseFriend
+	err := json.NewDecoder(r.Body).Decode(&friend)
+	if err != nil {
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if friend.UserID <= 0 || friend.FriendId <= 0 || friend.UserID == friend.FriendId {
+		http.Error(w, "Invalid ids or missing fields", http.StatusBadRequest)
+		return
+	}
+
+	var idexists bool
+	err = db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE user_id=$1)", friend.FriendId).Scan(&idexists)
+	if err != nil {
+		http.Error(w, "Invalid user-id", http.StatusInternalServerError)
+		return
+	}
+	if !idexists {
+		http.Error(w, "No user exists with this user-id", http.StatusBadRequest)
+		return
+	}
+
+	_, err = db.DB.Exec("INSERT INTO close_friends(user_id,friend_id) VALUES($1,$2) ON CONFLICT DO NOTHING", friend.UserID, friend.FriendId)
+	if err != nil {
+		http.Error(w, "Error adding close friend", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintln(w, "Added to close friends")
+}
+
+func RemoveCloseFriend(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var friend models.CloseFriend
+	err := json.NewDecoder(r.Body).Decode(&friend)
+	if err != nil {
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if friend.UserID <= 0 || friend.FriendId <= 0 {
+		http.Error(w, "Invalid ids or missing fields", http.StatusBadRequest)
+		return
+	}
+
+	_, err = db.DB.Exec("DELETE FROM close_friends WHERE user_id=$1 AND friend_id=$2", friend.UserID, friend.FriendId)
+	if err != nil {
+		http.Error(w, "Error removing close friend", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintln(w, "Removed from close friends")
+}
+
+func GetCloseFriends(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var userId models.UserID
+	err := json.NewDecoder(r.Body).Decode(&userId)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if userId.UserId <= 0 {
+		http.Error(w, "Invalid user id or missing field", http.StatusBadRequest)
+		return
+	}
+
+	row, err := db.DB.Query(`SELECT u.user_id,u.user_name,u.name,u.display_pic FROM close_friends c
+		JOIN users u ON u.user_id=c.friend_id
+		WHERE c.user_id=$1 ORDER BY u.user_name`, userId.UserId)
+	if err != nil {
+		http.Error(w, "Error retrieving close friends", http.StatusInternalServerError)
+		return
+	}
+	defer row.Close()
+
+	var friends []models.Accounts
+	for row.Next() {
+		var acc models.Accounts
+		err = row.Scan(&acc.UserID, &acc.UserName, &acc.Name, &acc.ProfilePic)
+		if err != nil {
+			http.Error(w, "Error reading close friends", http.StatusInternalServerError)
+			return
+		}
+		acc.ProfilePic = "http://localhost:3000/getProfilePic/" + acc.ProfilePic
+		friends = append(friends, acc)
+	}
+
+	json.NewEncoder(w).Encode(friends)
+}
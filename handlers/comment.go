@@ -3,11 +3,61 @@ package handlers
 import (
 	"backend/db"
 	"backend/models"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 )
 
+// checks that audience is one of the comment audience options
+func validCommentAudience(audience string) error {
+	switch audience {
+	case models.CommentAudienceEveryone, models.CommentAudienceFollowing, models.CommentAudienceFollowers, models.CommentAudienceMutual, models.CommentAudienceOff:
+		return nil
+	}
+	return errors.New("Comment audience should be everyone, following, followers, mutual or off")
+}
+
+// checks the comment settings of a post against the commenter
+func canComment(userId, postId int64) (bool, error) {
+	var ownerId int64
+	var hideComments bool
+	var audience string
+	err := db.DB.QueryRow("SELECT user_id,hide_comments,comment_audience FROM posts WHERE post_id=$1 AND complete_post=$2", postId, true).Scan(&ownerId, &hideComments, &audience)
+	if err != nil {
+		return false, err
+	}
+	if hideComments || audience == models.CommentAudienceOff {
+		return false, nil
+	}
+	if ownerId == userId {
+		return true, nil
+	}
+
+	visible, err := canViewProfile(userId, ownerId)
+	if err != nil || !visible {
+		return false, err
+	}
+
+	var ownerFollows, followsOwner bool
+	err = db.DB.QueryRow(`SELECT EXISTS(SELECT 1 FROM follower WHERE user_id=$1 AND follower_id=$2 AND accepted=$3),
+		EXISTS(SELECT 1 FROM follower WHERE user_id=$2 AND follower_id=$1 AND accepted=$3)`, ownerId, userId, true).Scan(&ownerFollows, &followsOwner)
+	if err != nil {
+		return false, err
+	}
+
+	switch audience {
+	case models.CommentAudienceFollowing:
+		return ownerFollows, nil
+	case models.CommentAudienceFollowers:
+		return followsOwner, nil
+	case models.CommentAudienceMutual:
+		return ownerFollows && followsOwner, nil
+	}
+	return true, nil
+}
+
 func CommentPost(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -34,22 +84,110 @@ func CommentPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	insertComment := `INSERT INTO comments(commentoruser_id,post_id,comment_body) VALUES($1,$2,$3) RETURNING comment_id`
+	allowed, err := canComment(requestBody.UserID, requestBody.PostId)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Invalid post id", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error checking comment settings", http.StatusInternalServerError)
+		return
+	}
+	if !allowed {
+		http.Error(w, "You can't comment on this post", http.StatusForbidden)
+		return
+	}
+
+	//replies are kept one level deep under the top level comment
+	var parentCommentId *int64
+	if requestBody.ParentCommentId > 0 {
+		var rootId int64
+		err = db.DB.QueryRow("SELECT COALESCE(parent_comment_id,comment_id) FROM comments WHERE comment_id=$1 AND post_id=$2", requestBody.ParentCommentId, requestBody.PostId).Scan(&rootId)
+		if err != nil {
+			http.Error(w, "Invalid parent comment id", http.StatusBadRequest)
+			return
+		}
+		parentCommentId = &rootId
+	}
+
+	//comments matching the owner's filter or from restricted accounts wait in the review queue
+	hidden, err := filteredComment(requestBody.PostId, requestBody.UserID, requestBody.CommentBody)
+	if err != nil {
+		http.Error(w, "Error checking comment filter", http.StatusInternalServerError)
+		return
+	}
+
+	insertComment := `INSERT INTO comments(commentoruser_id,post_id,comment_body,parent_comment_id,hidden) VALUES($1,$2,$3,$4,$5) RETURNING comment_id`
 	var returnedCommentId models.ReturnedCommentId
 
-	err = db.DB.QueryRow(insertComment, requestBody.UserID, requestBody.PostId, requestBody.CommentBody).Scan(&returnedCommentId.ReturnedCommentId)
+	err = db.DB.QueryRow(insertComment, requestBody.UserID, requestBody.PostId, requestBody.CommentBody, parentCommentId, hidden).Scan(&returnedCommentId.ReturnedCommentId)
 	if err != nil {
 		http.Error(w, "Invalid post id", http.StatusBadRequest)
+		return
 	}
 	json.NewEncoder(w).Encode(returnedCommentId)
 
 }
+
+// columns of a comment served in comment lists, $1 is the viewer id
+const commentColumns = `c.comment_id,c.post_id,c.parent_comment_id,u.user_name,u.display_pic,c.comment_body,c.commented_on,
+	(SELECT COUNT(r.comment_id) FROM comments r WHERE r.parent_comment_id=c.comment_id AND r.hidden=false),
+	(SELECT COUNT(l.user_id) FROM comment_likes l WHERE l.comment_id=c.comment_id),
+	EXISTS(SELECT 1 FROM comment_likes l WHERE l.comment_id=c.comment_id AND l.user_id=$1),c.hidden,c.edited_on IS NOT NULL,
+	EXISTS(SELECT 1 FROM pinned_comments pc WHERE pc.comment_id=c.comment_id)`
+
+// hidden comments are visible only to their author and comments across a block are not shown, $1 is the viewer id
+const visibleComment = `((c.hidden=false OR c.commentoruser_id=$1) AND NOT EXISTS(SELECT 1 FROM blocks b
+	WHERE (b.user_id=$1 AND b.blocked_id=c.commentoruser_id) OR (b.user_id=c.commentoruser_id AND b.blocked_id=$1)))`
+
+// runs a comment list query selecting commentColumns
+func queryComments(query string, args ...interface{}) ([]models.CommentsOfPost, error) {
+	row, err := db.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var comments []models.CommentsOfPost
+	for row.Next() {
+		var comment models.CommentsOfPost
+		var parentCommentId sql.NullInt64
+		var dpURL string
+		err = row.Scan(&comment.CommentId, &comment.PostId, &parentCommentId, &comment.CommentorUserName, &dpURL, &comment.CommentBody, &comment.CommentedOn, &comment.ReplyCount, &comment.LikeCount, &comment.LikeStatus, &comment.Hidden, &comment.Edited, &comment.Pinned)
+		if err != nil {
+			return nil, err
+		}
+		comment.ParentCommentId = parentCommentId.Int64
+		comment.CommentorDisplayPic = "http://localhost:3000/getProfilePic/" + dpURL
+		comments = append(comments, comment)
+	}
+	return comments, nil
+}
+
+// comments of a post with comments turned off are visible only to its owner, and never across a block
+func commentsVisible(postId, viewerId int64) (bool, error) {
+	var ownerId int64
+	var hideComments bool
+	err := db.DB.QueryRow("SELECT user_id,hide_comments FROM posts WHERE post_id=$1", postId).Scan(&ownerId, &hideComments)
+	if err != nil {
+		return false, err
+	}
+	if ownerId == viewerId {
+		return true, nil
+	}
+	isBlocked, err := blockedBetween(viewerId, ownerId)
+	if err != nil {
+		return false, err
+	}
+	return !hideComments && !isBlocked, nil
+}
+
 func AllComments(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	var postId models.PostId
+	var postId models.CommentsRequest
 	err := json.NewDecoder(r.Body).Decode(&postId)
 	if err != nil {
 		http.Error(w, "Invalid request body", http.StatusMethodNotAllowed)
@@ -60,37 +198,71 @@ func AllComments(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = db.DB.QueryRow("SELECT post_id FROM posts WHERE post_id=$1", postId.PostId).Scan(&postId.PostId)
+	visible, err := commentsVisible(postId.PostId, postId.UserID)
 	if err != nil {
 		http.Error(w, "Invalid post id", http.StatusInternalServerError)
 		return
 	}
+	if !visible {
+		json.NewEncoder(w).Encode([]models.CommentsOfPost{})
+		return
+	}
 
-	var comments []models.CommentsOfPost
-	var comment models.CommentsOfPost
-	row, err := db.DB.Query("SELECT commentoruser_id,comment_id,comment_body,commented_on FROM comments WHERE post_id=$1 ORDER BY commented_on DESC", postId.PostId)
+	//only top level comments, replies are served by CommentReplies, pinned comments come first
+	comments, err := queryComments(`SELECT `+commentColumns+` FROM comments c
+		JOIN users u ON u.user_id=c.commentoruser_id
+		LEFT JOIN pinned_comments pc ON pc.comment_id=c.comment_id
+		WHERE c.post_id=$2 AND c.parent_comment_id IS NULL AND `+visibleComment+` ORDER BY pc.pinned_on DESC NULLS LAST,c.commented_on DESC`, postId.UserID, postId.PostId)
 	if err != nil {
-		panic(err)
+		http.Error(w, "Error retrieving comments", http.StatusInternalServerError)
+		return
 	}
-	for row.Next() {
-		var commentorUSerID int64
-		err = row.Scan(&commentorUSerID, &comment.CommentId, &comment.CommentBody, &comment.CommentedOn)
-		if err != nil {
-			panic(err)
-		}
+	json.NewEncoder(w).Encode(comments)
 
-		var dpURL string
-		err = db.DB.QueryRow("SELECT user_name,display_pic FROM users WHERE user_id=$1", commentorUSerID).Scan(&comment.CommentorUserName, &dpURL)
-		if err != nil {
-			panic(err)
-		}
-		comment.CommentorDisplayPic = "http://localhost:3000/getProfilePic/" + dpURL
-		comment.PostId = postId.PostId
-		comments = append(comments, comment)
+}
 
+func CommentReplies(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var request models.CommentsRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if request.CommentId <= 0 {
+		http.Error(w, "Invalid comment id or missing field", http.StatusBadRequest)
+		return
+	}
+
+	var postId int64
+	err = db.DB.QueryRow("SELECT post_id FROM comments WHERE comment_id=$1 AND parent_comment_id IS NULL", request.CommentId).Scan(&postId)
+	if err != nil {
+		http.Error(w, "Invalid comment id", http.StatusBadRequest)
+		return
+	}
+
+	visible, err := commentsVisible(postId, request.UserID)
+	if err != nil {
+		http.Error(w, "Error retrieving post", http.StatusInternalServerError)
+		return
+	}
+	if !visible {
+		json.NewEncoder(w).Encode([]models.CommentsOfPost{})
+		return
 	}
-	json.NewEncoder(w).Encode(comments)
 
+	limit, offset := pageBounds(request.Page, request.Limit)
+	replies, err := queryComments(`SELECT `+commentColumns+` FROM comments c
+		JOIN users u ON u.user_id=c.commentoruser_id
+		WHERE c.parent_comment_id=$2 AND `+visibleComment+` ORDER BY c.commented_on ASC,c.comment_id ASC LIMIT $3 OFFSET $4`, request.UserID, request.CommentId, limit, offset)
+	if err != nil {
+		http.Error(w, "Error retrieving replies", http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(replies)
 }
 func TurnOffComments(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPut {
@@ -115,7 +287,7 @@ func TurnOffComments(w http.ResponseWriter, r *http.Request) {
 		panic(err)
 	}
 
-	_, err = db.DB.Query("UPDATE posts SET hide_comments=$1 WHERE user_id=$2", true, commentoff.UserID)
+	_, err = db.DB.Exec("UPDATE posts SET hide_comments=$1,comment_audience=$3 WHERE post_id=$2", true, commentoff.PostId, models.CommentAudienceOff)
 	if err != nil {
 		panic(err)
 	}
@@ -145,13 +317,95 @@ func TurnONComments(w http.ResponseWriter, r *http.Request) {
 		panic(err)
 	}
 
-	_, err = db.DB.Query("UPDATE posts SET hide_comments=$1 WHERE user_id=$2", false, commentoff.UserID)
+	//audience set before turning comments off is kept
+	_, err = db.DB.Exec("UPDATE posts SET hide_comments=$1,comment_audience=CASE WHEN comment_audience=$3 THEN $4 ELSE comment_audience END WHERE post_id=$2", false, commentoff.PostId, models.CommentAudienceOff, models.CommentAudienceEveryone)
 	if err != nil {
 		panic(err)
 	}
 	fmt.Fprintln(w, "Comments turned on")
 
 }
+func SetCommentAudience(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var audience models.CommentAudience
+	err := json.NewDecoder(r.Body).Decode(&audience)
+	if err != nil {
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if audience.PostId <= 0 || audience.UserID <= 0 {
+		http.Error(w, "Invalid ids or missing field", http.StatusBadRequest)
+		return
+	}
+	if err = validCommentAudience(audience.Audience); err != nil {
+		http.Error(w, fmt.Sprint(err), http.StatusBadRequest)
+		return
+	}
+
+	result, err := db.DB.Exec("UPDATE posts SET comment_audience=$1,hide_comments=$2 WHERE post_id=$3 AND user_id=$4", audience.Audience, audience.Audience == models.CommentAudienceOff, audience.PostId, audience.UserID)
+	if err != nil {
+		http.Error(w, "Error updating comment audience", http.StatusInternalServerError)
+		return
+	}
+	if updated, _ := result.RowsAffected(); updated == 0 {
+		http.Error(w, "Invalid Ids for operation", http.StatusBadRequest)
+		return
+	}
+	fmt.Fprintln(w, "Comment audience updated")
+}
+
+func SetDefaultCommentAudience(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var audience models.CommentAudience
+	err := json.NewDecoder(r.Body).Decode(&audience)
+	if err != nil {
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if audience.UserID <= 0 {
+		http.Error(w, "Invalid user id or missing field", http.StatusBadRequest)
+		return
+	}
+	if err = validCommentAudience(audience.Audience); err != nil {
+		http.Error(w, fmt.Sprint(err), http.StatusBadRequest)
+		return
+	}
+
+	result, err := db.DB.Exec("UPDATE users SET comment_audience=$1 WHERE user_id=$2", audience.Audience, audience.UserID)
+	if err != nil {
+		http.Error(w, "Error updating default comment audience", http.StatusInternalServerError)
+		return
+	}
+	if updated, _ := result.RowsAffected(); updated == 0 {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		return
+	}
+	fmt.Fprintln(w, "Default comment audience updated")
+}
+
+// deletes a comment with its replies and likes
+func removeComment(commentId int64) error {
+	//likes of the comment and its replies go with it
+	_, err := db.DB.Exec("DELETE FROM comment_likes WHERE comment_id IN (SELECT comment_id FROM comments WHERE comment_id=$1 OR parent_comment_id=$1)", commentId)
+	if err != nil {
+		return err
+	}
+
+	//replies are deleted along with their parent comment
+	_, err = db.DB.Exec("DELETE FROM comments WHERE comment_id=$1 OR parent_comment_id=$1", commentId)
+	return err
+}
+
 func DeleteComment(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -170,19 +424,20 @@ func DeleteComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = db.DB.QueryRow("SELECT user_id,post_id FROM posts WHERE user_id=$1 AND post_id=$2", deleteComment.UserID, deleteComment.PostId).Scan(&deleteComment.UserID, &deleteComment.PostId)
+	//the comment author or the post owner can delete a comment
+	var allowed bool
+	err = db.DB.QueryRow(`SELECT EXISTS(SELECT 1 FROM comments c JOIN posts p ON p.post_id=c.post_id
+		WHERE c.comment_id=$1 AND c.post_id=$2 AND (c.commentoruser_id=$3 OR p.user_id=$3))`, deleteComment.CommentId, deleteComment.PostId, deleteComment.UserID).Scan(&allowed)
 	if err != nil {
-		http.Error(w, "Invalid Ids for operation", http.StatusInternalServerError)
+		http.Error(w, "Error retrieving comment", http.StatusInternalServerError)
 		return
 	}
-
-	err = db.DB.QueryRow("SELECT post_id,comment_id FROM comments WHERE post_id=$1 AND comment_id=$2", deleteComment.PostId, deleteComment.CommentId).Scan(&deleteComment.PostId, &deleteComment.CommentId)
-	if err != nil {
-		http.Error(w, "Invalid Ids for operation", http.StatusInternalServerError)
+	if !allowed {
+		http.Error(w, "Invalid Ids for operation", http.StatusBadRequest)
 		return
 	}
 
-	_, err = db.DB.Query("DELETE FROM comments WHERE post_id=$1 AND comment_id=$2", deleteComment.PostId, deleteComment.CommentId)
+	err = removeComment(deleteComment.CommentId)
 	if err != nil {
 		http.Error(w, "error deleting comment", http.StatusInternalServerError)
 		return
@@ -191,3 +446,288 @@ func DeleteComment(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintln(w, "Comment deleted succefully")
 
 }
+
+const maxPinnedComments = 3
+
+func PinComment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var pin models.PinComment
+	err := json.NewDecoder(r.Body).Decode(&pin)
+	if err != nil {
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if pin.UserID <= 0 || pin.CommentId <= 0 {
+		http.Error(w, "Invalid ids or missing fields", http.StatusBadRequest)
+		return
+	}
+
+	//only visible top level comments on my own post can be pinned
+	var postId int64
+	err = db.DB.QueryRow(`SELECT c.post_id FROM comments c JOIN posts p ON p.post_id=c.post_id
+		WHERE c.comment_id=$1 AND p.user_id=$2 AND c.parent_comment_id IS NULL AND c.hidden=false`, pin.CommentId, pin.UserID).Scan(&postId)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Invalid Ids for operation", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error retrieving comment", http.StatusInternalServerError)
+		return
+	}
+
+	var count int
+	err = db.DB.QueryRow("SELECT COUNT(comment_id) FROM pinned_comments WHERE post_id=$1 AND comment_id<>$2", postId, pin.CommentId).Scan(&count)
+	if err != nil {
+		http.Error(w, "Error retrieving pinned comments", http.StatusInternalServerError)
+		return
+	}
+	if count >= maxPinnedComments {
+		http.Error(w, "Only 3 comments can be pinned on a post", http.StatusBadRequest)
+		return
+	}
+
+	_, err = db.DB.Exec("INSERT INTO pinned_comments(comment_id,post_id) VALUES($1,$2) ON CONFLICT DO NOTHING", pin.CommentId, postId)
+	if err != nil {
+		http.Error(w, "Error pinning comment", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintln(w, "Comment pinned")
+}
+
+func UnpinComment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var pin models.PinComment
+	err := json.NewDecoder(r.Body).Decode(&pin)
+	if err != nil {
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if pin.UserID <= 0 || pin.CommentId <= 0 {
+		http.Error(w, "Invalid ids or missing fields", http.StatusBadRequest)
+		return
+	}
+
+	result, err := db.DB.Exec("DELETE FROM pinned_comments pc USING posts p WHERE p.post_id=pc.post_id AND pc.comment_id=$1 AND p.user_id=$2", pin.CommentId, pin.UserID)
+	if err != nil {
+		http.Error(w, "Error unpinning comment", http.StatusInternalServerError)
+		return
+	}
+	if deleted, _ := result.RowsAffected(); deleted == 0 {
+		http.Error(w, "Invalid Ids for operation", http.StatusBadRequest)
+		return
+	}
+	fmt.Fprintln(w, "Comment unpinned")
+}
+
+// how long after posting a comment its author can edit it
+const commentEditWindow = "15 minutes"
+
+func EditComment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var edit models.EditComment
+	err := json.NewDecoder(r.Body).Decode(&edit)
+	if err != nil {
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if edit.UserID <= 0 || edit.CommentId <= 0 {
+		http.Error(w, "Invalid ids or missing fields", http.StatusBadRequest)
+		return
+	}
+	if edit.CommentBody == "" || len(edit.CommentBody) > 2500 {
+		http.Error(w, "Comment body should be of length(1,2500)", http.StatusBadRequest)
+		return
+	}
+
+	//only the author edits, and only within the edit window
+	var postId int64
+	var body string
+	var editable bool
+	err = db.DB.QueryRow("SELECT post_id,comment_body,commented_on > current_timestamp - $2::interval FROM comments WHERE comment_id=$1 AND commentoruser_id=$3", edit.CommentId, commentEditWindow, edit.UserID).Scan(&postId, &body, &editable)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Invalid Ids for operation", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error retrieving comment", http.StatusInternalServerError)
+		return
+	}
+	if !editable {
+		http.Error(w, "Comments can be edited only within 15 minutes of posting", http.StatusForbidden)
+		return
+	}
+	if body == edit.CommentBody {
+		fmt.Fprintln(w, "Comment edited")
+		return
+	}
+
+	//edited text goes through the owner's filter again
+	hidden, err := filteredComment(postId, edit.UserID, edit.CommentBody)
+	if err != nil {
+		http.Error(w, "Error checking comment filter", http.StatusInternalServerError)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "Error editing comment", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("INSERT INTO comment_edits(comment_id,comment_body) VALUES($1,$2)", edit.CommentId, body)
+	if err != nil {
+		http.Error(w, "Error saving comment history", http.StatusInternalServerError)
+		return
+	}
+
+	_, err = tx.Exec("UPDATE comments SET comment_body=$1,edited_on=current_timestamp,hidden=hidden OR $2 WHERE comment_id=$3", edit.CommentBody, hidden, edit.CommentId)
+	if err != nil {
+		http.Error(w, "Error editing comment", http.StatusInternalServerError)
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		http.Error(w, "Error editing comment", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintln(w, "Comment edited")
+}
+
+func CommentHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request models.CommentsRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if request.CommentId <= 0 {
+		http.Error(w, "Invalid comment id or missing field", http.StatusBadRequest)
+		return
+	}
+
+	var history models.CommentHistory
+	var postId, authorId int64
+	var hidden bool
+	var editedOn sql.NullString
+	err = db.DB.QueryRow("SELECT comment_id,post_id,commentoruser_id,comment_body,edited_on,hidden FROM comments WHERE comment_id=$1", request.CommentId).Scan(&history.CommentId, &postId, &authorId, &history.CommentBody, &editedOn, &hidden)
+	if err != nil {
+		http.Error(w, "Invalid comment id", http.StatusBadRequest)
+		return
+	}
+	history.EditedOn = editedOn.String
+
+	//history is visible to whoever can see the comment
+	visible, err := commentsVisible(postId, request.UserID)
+	if err != nil {
+		http.Error(w, "Error retrieving post", http.StatusInternalServerError)
+		return
+	}
+	if !visible || (hidden && authorId != request.UserID) {
+		http.Error(w, "Invalid comment id", http.StatusBadRequest)
+		return
+	}
+
+	row, err := db.DB.Query("SELECT comment_body,edited_on FROM comment_edits WHERE comment_id=$1 ORDER BY edited_on ASC,edit_id ASC", request.CommentId)
+	if err != nil {
+		http.Error(w, "Error retrieving comment history", http.StatusInternalServerError)
+		return
+	}
+	defer row.Close()
+
+	history.Edits = []models.CommentEdit{}
+	for row.Next() {
+		var edit models.CommentEdit
+		err = row.Scan(&edit.CommentBody, &edit.EditedOn)
+		if err != nil {
+			http.Error(w, "Error reading comment history", http.StatusInternalServerError)
+			return
+		}
+		history.Edits = append(history.Edits, edit)
+	}
+	json.NewEncoder(w).Encode(history)
+}
+
+func LikeComment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var like models.LikeComment
+	err := json.NewDecoder(r.Body).Decode(&like)
+	if err != nil {
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if like.UserID <= 0 || like.CommentId <= 0 {
+		http.Error(w, "Invalid ids or missing fields", http.StatusBadRequest)
+		return
+	}
+
+	var authorId int64
+	err = db.DB.QueryRow("SELECT commentoruser_id FROM comments WHERE comment_id=$1", like.CommentId).Scan(&authorId)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Invalid comment id", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error retrieving comment", http.StatusInternalServerError)
+		return
+	}
+
+	isBlocked, err := blockedBetween(like.UserID, authorId)
+	if err != nil {
+		http.Error(w, "Error retrieving comment", http.StatusInternalServerError)
+		return
+	}
+	if isBlocked {
+		http.Error(w, "You can't like this comment", http.StatusForbidden)
+		return
+	}
+
+	status := models.CommentLikeStatus{CommentId: like.CommentId, LikeStatus: true}
+
+	//toggle, a second like removes it
+	result, err := db.DB.Exec("INSERT INTO comment_likes(comment_id,user_id) VALUES($1,$2) ON CONFLICT DO NOTHING", like.CommentId, like.UserID)
+	if err != nil {
+		http.Error(w, "Error liking comment", http.StatusInternalServerError)
+		return
+	}
+	if inserted, _ := result.RowsAffected(); inserted == 0 {
+		_, err = db.DB.Exec("DELETE FROM comment_likes WHERE comment_id=$1 AND user_id=$2", like.CommentId, like.UserID)
+		if err != nil {
+			http.Error(w, "Error unliking comment", http.StatusInternalServerError)
+			return
+		}
+		status.LikeStatus = false
+	}
+
+	err = db.DB.QueryRow("SELECT COUNT(user_id) FROM comment_likes WHERE comment_id=$1", like.CommentId).Scan(&status.TotalLikes)
+	if err != nil {
+		http.Error(w, "Error retrieving comment likes", http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(status)
+}
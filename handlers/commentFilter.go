@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"backend/db"
+	"backend/models"
+	"backend/src"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/lib/pq"
+)
+
+// returns the comment filter of a user, offensive words are hidden by default
+func getCommentFilter(userId int64) (models.CommentFilter, error) {
+	filter := models.CommentFilter{UserID: userId, Keywords: []string{}}
+	hideOffensive := true
+	err := db.DB.QueryRow("SELECT hide_offensive,keywords FROM comment_filters WHERE user_id=$1", userId).Scan(&hideOffensive, pq.Array(&filter.Keywords))
+	if err != nil && err != sql.ErrNoRows {
+		return filter, err
+	}
+	filter.HideOffensive = &hideOffensive
+	return filter, nil
+}
+
+// checks a new comment against the filter of the post owner, comments by accounts the owner restricted are always held
+// and the owner's own comments never are
+func filteredComment(postId, userId int64, body string) (bool, error) {
+	var ownerId int64
+	var restricted bool
+	err := db.DB.QueryRow("SELECT p.user_id,EXISTS(SELECT 1 FROM restricts r WHERE r.user_id=p.user_id AND r.restricted_id=$2) FROM posts p WHERE p.post_id=$1", postId, userId).Scan(&ownerId, &restricted)
+	if err != nil {
+		return false, err
+	}
+	if ownerId == userId {
+		return false, nil
+	}
+	if restricted {
+		return true, nil
+	}
+
+	filter, err := getCommentFilter(ownerId)
+	if err != nil {
+		return false, err
+	}
+	return src.MatchesCommentFilter(body, *filter.HideOffensive, filter.Keywords), nil
+}
+
+func SetCommentFilter(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var filter models.CommentFilter
+	err := json.NewDecoder(r.Body).Decode(&filter)
+	if err != nil {
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if filter.UserID <= 0 || filter.HideOffensive == nil {
+		http.Error(w, "Invalid user id or missing fields", http.StatusBadRequest)
+		return
+	}
+
+	filter.Keywords, err = src.ValidateFilterKeywords(filter.Keywords)
+	if err != nil {
+		http.Error(w, fmt.Sprint(err), http.StatusBadRequest)
+		return
+	}
+
+	_, err = db.DB.Exec(`INSERT INTO comment_filters(user_id,hide_offensive,keywords) VALUES($1,$2,$3)
+		ON CONFLICT (user_id) DO UPDATE SET hide_offensive=EXCLUDED.hide_offensive,keywords=EXCLUDED.keywords`, filter.UserID, *filter.HideOffensive, pq.Array(filter.Keywords))
+	if err != nil {
+		http.Error(w, "Error updating comment filter", http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(filter)
+}
+
+func GetCommentFilter(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var userId models.UserID
+	err := json.NewDecoder(r.Body).Decode(&userId)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if userId.UserId <= 0 {
+		http.Error(w, "Invalid user id or missing field", http.StatusBadRequest)
+		return
+	}
+
+	filter, err := getCommentFilter(userId.UserId)
+	if err != nil {
+		http.Error(w, "Error retrieving comment filter", http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(filter)
+}
+
+func HiddenComments(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request models.CommentsRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if request.UserID <= 0 {
+		http.Error(w, "Invalid user id or missing field", http.StatusBadRequest)
+		return
+	}
+
+	//hidden comments on all posts of the owner, or on one post when post_id is given
+	limit, offset := pageBounds(request.Page, request.Limit)
+	comments, err := queryComments(`SELECT `+commentColumns+` FROM comments c
+		JOIN users u ON u.user_id=c.commentoruser_id
+		JOIN posts p ON p.post_id=c.post_id
+		WHERE p.user_id=$1 AND c.hidden=true AND ($2=0 OR c.post_id=$2)
+		ORDER BY c.commented_on DESC LIMIT $3 OFFSET $4`, request.UserID, request.PostId, limit, offset)
+	if err != nil {
+		http.Error(w, "Error retrieving hidden comments", http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(comments)
+}
+
+func ReviewHiddenComment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var review models.ReviewComment
+	err := json.NewDecoder(r.Body).Decode(&review)
+	if err != nil {
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if review.UserID <= 0 || review.CommentId <= 0 {
+		http.Error(w, "Invalid ids or missing fields", http.StatusBadRequest)
+		return
+	}
+
+	//only the post owner reviews hidden comments on their posts
+	var exists bool
+	err = db.DB.QueryRow(`SELECT EXISTS(SELECT 1 FROM comments c JOIN posts p ON p.post_id=c.post_id
+		WHERE c.comment_id=$1 AND c.hidden=true AND p.user_id=$2)`, review.CommentId, review.UserID).Scan(&exists)
+	if err != nil {
+		http.Error(w, "Error retrieving comment", http.StatusInternalServerError)
+		return
+	}
+	if !exists {
+		http.Error(w, "Invalid Ids for operation", http.StatusBadRequest)
+		return
+	}
+
+	if review.Approve {
+		_, err = db.DB.Exec("UPDATE comments SET hidden=$1 WHERE comment_id=$2", false, review.CommentId)
+		if err != nil {
+			http.Error(w, "Error approving comment", http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintln(w, "Comment approved")
+		return
+	}
+
+	err = removeComment(review.CommentId)
+	if err != nil {
+		http.Error(w, "error deleting comment", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintln(w, "Comment deleted succefully")
+}
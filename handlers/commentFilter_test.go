@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"testing"
+)
+
+func TestFilteredCommentHidesKeywordMatch(t *testing.T) {
+	expectQueries(t,
+		fakeQuery{sql: "FROM restricts r WHERE r.user_id=p.user_id", columns: []string{"user_id", "restricted"}, rows: [][]driver.Value{{int64(2), false}}},
+		fakeQuery{sql: "FROM comment_filters", args: []driver.Value{int64(2)}, columns: []string{"hide_offensive", "keywords"},
+			rows: [][]driver.Value{{true, []byte("{spoiler}")}}},
+	)
+
+	hidden, err := filteredComment(1, 7, "huge spoiler ahead")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hidden {
+		t.Error("expected the comment to be hidden")
+	}
+}
+
+func TestFilteredCommentSkipsPostOwner(t *testing.T) {
+	expectQueries(t,
+		fakeQuery{sql: "FROM restricts r WHERE r.user_id=p.user_id", columns: []string{"user_id", "restricted"}, rows: [][]driver.Value{{int64(2), false}}},
+	)
+
+	hidden, err := filteredComment(1, 2, "huge spoiler ahead")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hidden {
+		t.Error("expected the owner's comment to be shown")
+	}
+}
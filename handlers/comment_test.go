@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"backend/models"
+	"database/sql/driver"
+	"net/http"
+	"testing"
+)
+
+func TestCommentPostRejectsOutsideAudience(t *testing.T) {
+	expectQueries(t,
+		fakeQuery{sql: "SELECT user_id,hide_comments,comment_audience FROM posts", columns: []string{"user_id", "hide_comments", "comment_audience"},
+			rows: [][]driver.Value{{int64(2), false, models.CommentAudienceMutual}}},
+		fakeQuery{sql: "FROM blocks", columns: []string{"blocked"}, rows: [][]driver.Value{{false}}},
+		fakeQuery{sql: "SELECT private FROM users", columns: []string{"private"}, rows: [][]driver.Value{{false}}},
+		//the commenter follows the owner but is not followed back
+		fakeQuery{sql: "FROM follower WHERE user_id=$1 AND follower_id=$2", args: []driver.Value{int64(2), int64(7), true},
+			columns: []string{"owner_follows", "follows_owner"}, rows: [][]driver.Value{{false, true}}},
+	)
+
+	w := serve(CommentPost, http.MethodPost, models.CommentBody{UserID: 7, PostId: 1, CommentBody: "nice"})
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected %d, got %d: %s", http.StatusForbidden, w.Code, w.Body)
+	}
+}
+
+func TestCommentPostAllowsMutualFollower(t *testing.T) {
+	expectQueries(t,
+		fakeQuery{sql: "SELECT user_id,hide_comments,comment_audience FROM posts", columns: []string{"user_id", "hide_comments", "comment_audience"},
+			rows: [][]driver.Value{{int64(2), false, models.CommentAudienceMutual}}},
+		fakeQuery{sql: "FROM blocks", columns: []string{"blocked"}, rows: [][]driver.Value{{false}}},
+		fakeQuery{sql: "SELECT private FROM users", columns: []string{"private"}, rows: [][]driver.Value{{false}}},
+		fakeQuery{sql: "FROM follower WHERE user_id=$1 AND follower_id=$2", columns: []string{"owner_follows", "follows_owner"}, rows: [][]driver.Value{{true, true}}},
+		fakeQuery{sql: "FROM restricts r WHERE r.user_id=p.user_id", columns: []string{"user_id", "restricted"}, rows: [][]driver.Value{{int64(2), false}}},
+		fakeQuery{sql: "FROM comment_filters", columns: []string{"hide_offensive", "keywords"}},
+		fakeQuery{sql: "INSERT INTO comments", args: []driver.Value{int64(7), int64(1), "nice", nil, false}, columns: []string{"comment_id"}, rows: [][]driver.Value{{int64(9)}}},
+	)
+
+	w := serve(CommentPost, http.MethodPost, models.CommentBody{UserID: 7, PostId: 1, CommentBody: "nice"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body)
+	}
+}
+
+func TestCommentPostRejectsOwnerWhenCommentsOff(t *testing.T) {
+	expectQueries(t,
+		fakeQuery{sql: "SELECT user_id,hide_comments,comment_audience FROM posts", columns: []string{"user_id", "hide_comments", "comment_audience"},
+			rows: [][]driver.Value{{int64(2), true, models.CommentAudienceOff}}},
+	)
+
+	w := serve(CommentPost, http.MethodPost, models.CommentBody{UserID: 2, PostId: 1, CommentBody: "nice"})
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected %d, got %d: %s", http.StatusForbidden, w.Code, w.Body)
+	}
+}
+
+func TestSetCommentAudienceRejectsOtherUsersPost(t *testing.T) {
+	expectQueries(t,
+		fakeQuery{sql: "UPDATE posts SET comment_audience=$1", affected: 0},
+	)
+
+	w := serve(SetCommentAudience, http.MethodPut, models.CommentAudience{UserID: 7, PostId: 1, Audience: models.CommentAudienceFollowers})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d, got %d: %s", http.StatusBadRequest, w.Code, w.Body)
+	}
+}
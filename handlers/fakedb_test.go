@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"backend/db"
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// one statement the fake database expects, statements are matched in order by a fragment of their sql
+type fakeQuery struct {
+	sql      string
+	args     []driver.Value // compared when not nil
+	columns  []string
+	rows     [][]driver.Value
+	affected int64
+	err      error
+}
+
+// a scripted database standing in for postgres in handler tests
+type fakeDB struct {
+	t       *testing.T
+	mu      sync.Mutex
+	queries []fakeQuery
+}
+
+var fakeDBs sync.Map
+
+func init() {
+	sql.Register("fakedb", fakeDriver{})
+}
+
+// points db.DB at a fake database expecting the given statements, in order, for the rest of the test
+func expectQueries(t *testing.T, queries ...fakeQuery) {
+	t.Helper()
+	fake := &fakeDB{t: t, queries: queries}
+	fakeDBs.Store(t.Name(), fake)
+
+	conn, err := sql.Open("fakedb", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := db.DB
+	db.DB = conn
+	t.Cleanup(func() {
+		conn.Close()
+		db.DB = previous
+		fakeDBs.Delete(t.Name())
+		if len(fake.queries) > 0 {
+			t.Errorf("statement never ran: %s", fake.queries[0].sql)
+		}
+	})
+}
+
+// pops the next expected statement and checks it against the one being run
+func (fake *fakeDB) next(query string, args []driver.NamedValue) (fakeQuery, error) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	if len(fake.queries) == 0 {
+		fake.t.Errorf("unexpected statement: %s", query)
+		return fakeQuery{}, errors.New("unexpected statement")
+	}
+	expected := fake.queries[0]
+	fake.queries = fake.queries[1:]
+
+	if !strings.Contains(query, expected.sql) {
+		fake.t.Errorf("expected statement containing %q, got %s", expected.sql, query)
+		return fakeQuery{}, errors.New("unexpected statement")
+	}
+	if expected.args != nil {
+		values := make([]driver.Value, len(args))
+		for i, arg := range args {
+			values[i] = arg.Value
+		}
+		if !reflect.DeepEqual(values, expected.args) {
+			fake.t.Errorf("statement %q ran with %v, expected %v", expected.sql, values, expected.args)
+		}
+	}
+	return expected, expected.err
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	fake, ok := fakeDBs.Load(name)
+	if !ok {
+		return nil, errors.New("no fake database for " + name)
+	}
+	return &fakeConn{fake.(*fakeDB)}, nil
+}
+
+type fakeConn struct {
+	fake *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{c, query}, nil
+}
+
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	expected, err := c.fake.next(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{columns: expected.columns, rows: expected.rows}, nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	expected, err := c.fake.next(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(expected.affected), nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.ExecContext(context.Background(), s.query, named(args))
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.QueryContext(context.Background(), s.query, named(args))
+}
+
+func named(args []driver.Value) []driver.NamedValue {
+	values := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		values[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return values
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// runs a handler with a json body and returns the recorded response
+func serve(handler http.HandlerFunc, method string, body interface{}) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(method, "/", bytes.NewReader(payload)))
+	return w
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"backend/models"
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestGetFollowingChecksViewerFollows(t *testing.T) {
+	expectQueries(t,
+		fakeQuery{sql: "FROM follower f JOIN users u ON u.user_id=f.follower_id", args: []driver.Value{int64(2), int64(7), true},
+			columns: []string{"user_id", "name", "user_name", "display_pic", "following_back"},
+			rows:    [][]driver.Value{{int64(3), "Three", "three", "3.jpg", true}, {int64(4), "Four", "four", "4.jpg", false}}},
+	)
+
+	w := serve(GetFollowing, http.MethodGet, models.ProfileView{UserId: 2, ViewerId: 7})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body)
+	}
+	var following []models.Follows
+	if err := json.NewDecoder(w.Body).Decode(&following); err != nil {
+		t.Fatal(err)
+	}
+	if len(following) != 2 || !following[0].FollowingBackStatus || following[1].FollowingBackStatus {
+		t.Errorf("unexpected following list: %+v", following)
+	}
+}
+
+func TestGetFollowingDefaultsViewerToOwner(t *testing.T) {
+	expectQueries(t,
+		fakeQuery{sql: "FROM follower f JOIN users u ON u.user_id=f.follower_id", args: []driver.Value{int64(2), int64(2), true},
+			columns: []string{"user_id", "name", "user_name", "display_pic", "following_back"}},
+	)
+
+	w := serve(GetFollowing, http.MethodGet, models.ProfileView{UserId: 2})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body)
+	}
+}
+
+func TestUpdateProfileHiddenFromEitherSideOfBlock(t *testing.T) {
+	expectQueries(t,
+		fakeQuery{sql: "(user_id=$1 AND blocked_id=$2) OR (user_id=$2 AND blocked_id=$1)", args: []driver.Value{int64(7), int64(2)},
+			columns: []string{"blocked"}, rows: [][]driver.Value{{true}}},
+	)
+
+	w := serve(UpdateProfile, http.MethodGet, models.ProfileView{UserId: 2, ViewerId: 7})
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected %d, got %d: %s", http.StatusNotFound, w.Code, w.Body)
+	}
+}
@@ -14,6 +14,23 @@ import (
 
 const MB = 1 << 20
 
+const defaultPageSize = 20
+const maxPageSize = 100
+
+// returns limit and offset of a page, pages start from 1
+func pageBounds(page, limit int) (int, int) {
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	if page <= 0 {
+		page = 1
+	}
+	return limit, (page - 1) * limit
+}
+
 func GetPost(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -21,9 +38,10 @@ func GetPost(w http.ResponseWriter, r *http.Request) {
 	}
 
 	//:var id int64
-	idstr := fmt.Sprint(r.URL)
+	_, idstr := path.Split(r.URL.Path)
 
-	_, idstr = path.Split(idstr)
+	//viewer is passed as ?user_id= to apply the owner's like count setting
+	viewerId, _ := strconv.ParseInt(r.URL.Query().Get("user_id"), 10, 64)
 
 	postId, err := strconv.Atoi(idstr)
 	if err != nil {
@@ -41,14 +59,24 @@ func GetPost(w http.ResponseWriter, r *http.Request) {
 	post.PostId = int64(postId)
 
 	var postURL string
-	query := `SELECT user_id,post_path,poat_caption,location,hide_like,hide_comments,posted_on FROM posts WHERE post_id=$1 AND complete_post=$2`
-	err = db.DB.QueryRow(query, postId, true).Scan(&post.UserID, &postURL, &post.PostCaption, &post.AttachedLocation, &post.HideLikeCount, &post.TurnOffComments, &post.PostedOn)
+	query := `SELECT user_id,post_path,poat_caption,location,hide_like,hide_comments,comment_audience,posted_on FROM posts WHERE post_id=$1 AND complete_post=$2`
+	err = db.DB.QueryRow(query, postId, true).Scan(&post.UserID, &postURL, &post.PostCaption, &post.AttachedLocation, &post.HideLikeCount, &post.TurnOffComments, &post.CommentAudience, &post.PostedOn)
 	if err != nil {
 		// http.Error(w, "Error fetching data from db posts", http.StatusInternalServerError)
 		// return
 		panic(err)
 	}
 
+	isBlocked, err := blockedBetween(viewerId, post.UserID)
+	if err != nil {
+		http.Error(w, "Error retriving post", http.StatusInternalServerError)
+		return
+	}
+	if isBlocked {
+		http.Error(w, "Invalid postId or does not exist", http.StatusNotFound)
+		return
+	}
+
 	filetype := strings.Split(postURL, ".")
 	post.FileType = models.GetExtension("." + filetype[len(filetype)-1])
 
@@ -63,13 +91,18 @@ func GetPost(w http.ResponseWriter, r *http.Request) {
 	}
 	post.UserProfilePicURL = "http://localhost:3000/getProfilePic/" + URL
 
-	err = db.DB.QueryRow("SELECT COUNT(user_name) FROM likes WHERE post_id=$1", postId).Scan(&post.Likes)
+	post.Likes, post.Reactions, err = visibleReactions(post.PostId, viewerId)
 	if err != nil {
 		http.Error(w, "Error retriving likes count", http.StatusInternalServerError)
 		return
 	}
 
-	//pending : update like status
+	post.Reaction, err = postReaction(post.PostId, viewerId)
+	if err != nil {
+		http.Error(w, "Error retriving like status", http.StatusInternalServerError)
+		return
+	}
+	post.LikeStatus = post.Reaction != ""
 
 	err = json.NewEncoder(w).Encode(post)
 	if err != nil {
@@ -114,7 +147,9 @@ func SearchAccounts(w http.ResponseWriter, r *http.Request) {
 	if len(name) != 0 && len(number) != 0 {
 		like = "%" + name[0] + "%" + number[0] + "%"
 	}
-	row, err := db.DB.Query("SELECT user_id,user_name,name,display_pic FROM users WHERE user_name ILIKE $1", like)
+	//accounts across a block with the searcher are left out
+	row, err := db.DB.Query(`SELECT user_id,user_name,name,display_pic FROM users u WHERE user_name ILIKE $1
+		AND NOT EXISTS(SELECT 1 FROM blocks b WHERE (b.user_id=$2 AND b.blocked_id=u.user_id) OR (b.user_id=u.user_id AND b.blocked_id=$2))`, like, username.UserID)
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,472 @@
+package handlers
+
+import (
+	"backend/db"
+	"backend/models"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const maxHighlightStories = 100
+
+// validates highlight title and checks that all story ids belong to the user
+func validateHighlight(userId int64, title *string, storyIds []int64) error {
+	if title != nil {
+		if *title == "" {
+			return errors.New("Highlight title cannot be empty")
+		}
+		if len(*title) > 15 {
+			return errors.New("Highlight title should not exceed 15 characters")
+		}
+	}
+
+	if storyIds == nil {
+		return nil
+	}
+	if len(storyIds) == 0 {
+		return errors.New("Highlight should have atleast one story")
+	}
+	if len(storyIds) > maxHighlightStories {
+		return errors.New("Only 100 stories allowed in a highlight")
+	}
+
+	seen := make(map[int64]bool)
+	for _, id := range storyIds {
+		if seen[id] {
+			return errors.New("Duplicate story id in highlight")
+		}
+		seen[id] = true
+	}
+
+	var count int
+	err := db.DB.QueryRow("SELECT COUNT(story_id) FROM stories WHERE user_id=$1 AND success=$2 AND story_id=ANY($3)", userId, true, pq.Array(storyIds)).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count != len(storyIds) {
+		return errors.New("Stories should be from your own archive")
+	}
+	return nil
+}
+
+// replaces the stories of a highlight keeping the given order
+func setHighlightStories(tx *sql.Tx, highlightId int64, storyIds []int64) error {
+	_, err := tx.Exec("DELETE FROM highlight_stories WHERE highlight_id=$1", highlightId)
+	if err != nil {
+		return err
+	}
+	for position, id := range storyIds {
+		_, err = tx.Exec("INSERT INTO highlight_stories(highlight_id,story_id,position) VALUES($1,$2,$3)", highlightId, id, position)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checks that the highlight exists and belongs to the user
+func highlightOwner(userId, highlightId int64) (bool, error) {
+	var exists bool
+	err := db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM highlights WHERE highlight_id=$1 AND user_id=$2)", highlightId, userId).Scan(&exists)
+	return exists, err
+}
+
+// returns all highlights of a user with their ordered story ids, cover urls carry the viewer for DownloadStory
+func getHighlights(userId, viewerId int64) ([]models.Highlight, error) {
+	row, err := db.DB.Query("SELECT highlight_id,title,cover_path,created_on FROM highlights WHERE user_id=$1 ORDER BY created_on DESC", userId)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var highlights []models.Highlight
+	index := make(map[int64]int)
+	for row.Next() {
+		var highlight models.Highlight
+		err = row.Scan(&highlight.HighlightId, &highlight.Title, &highlight.CoverURL, &highlight.CreatedOn)
+		if err != nil {
+			return nil, err
+		}
+		index[highlight.HighlightId] = len(highlights)
+		highlights = append(highlights, highlight)
+	}
+
+	//close friends stories are listed only to the owner and their list
+	stories, err := db.DB.Query(`SELECT h.highlight_id,h.story_id,s.story_path FROM highlight_stories h
+		JOIN stories s ON s.story_id=h.story_id
+		JOIN highlights hl ON hl.highlight_id=h.highlight_id
+		WHERE hl.user_id=$1 AND (hl.user_id=$2 OR s.audience<>$3 OR EXISTS(SELECT 1 FROM close_friends c WHERE c.user_id=hl.user_id AND c.friend_id=$2))
+		ORDER BY h.highlight_id,h.position`, userId, viewerId, models.StoryAudienceCloseFriends)
+	if err != nil {
+		return nil, err
+	}
+	defer stories.Close()
+
+	for stories.Next() {
+		var highlightId, storyId int64
+		var storyPath string
+		err = stories.Scan(&highlightId, &storyId, &storyPath)
+		if err != nil {
+			return nil, err
+		}
+		i, ok := index[highlightId]
+		if !ok {
+			continue
+		}
+		//first story is the cover when no cover is uploaded
+		if highlights[i].CoverURL == "" {
+			highlights[i].CoverURL = fmt.Sprintf("%s?story_id=%d&user_id=%d", storyPath, storyId, viewerId)
+		}
+		highlights[i].StoryIds = append(highlights[i].StoryIds, storyId)
+	}
+
+	visible := highlights[:0]
+	for _, highlight := range highlights {
+		//highlights made only of stories hidden from the viewer are left out
+		if len(highlight.StoryIds) == 0 && viewerId != userId {
+			continue
+		}
+		if highlight.CoverURL != "" {
+			highlight.CoverURL = "http://localhost:3000/download/" + highlight.CoverURL
+		}
+		visible = append(visible, highlight)
+	}
+	return visible, nil
+}
+
+func CreateHighlight(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var highlight models.HighlightInfo
+	err := json.NewDecoder(r.Body).Decode(&highlight)
+	if err != nil {
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if highlight.UserID <= 0 || highlight.Title == nil || highlight.StoryIds == nil {
+		http.Error(w, "Invalid user id or missing fields", http.StatusBadRequest)
+		return
+	}
+
+	if err = validateHighlight(highlight.UserID, highlight.Title, highlight.StoryIds); err != nil {
+		http.Error(w, fmt.Sprint(err), http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "Error creating highlight", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var returnedId models.ReturnedHighlightId
+	err = tx.QueryRow("INSERT INTO highlights(user_id,title) VALUES($1,$2) RETURNING highlight_id", highlight.UserID, *highlight.Title).Scan(&returnedId.HighlightId)
+	if err != nil {
+		http.Error(w, "Error creating highlight", http.StatusInternalServerError)
+		return
+	}
+
+	if err = setHighlightStories(tx, returnedId.HighlightId, highlight.StoryIds); err != nil {
+		http.Error(w, "Error adding stories to highlight", http.StatusInternalServerError)
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		http.Error(w, "Error creating highlight", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(returnedId)
+}
+
+func EditHighlight(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var highlight models.HighlightInfo
+	err := json.NewDecoder(r.Body).Decode(&highlight)
+	if err != nil {
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if highlight.UserID <= 0 || highlight.HighlightId <= 0 {
+		http.Error(w, "Invalid ids or missing fields", http.StatusBadRequest)
+		return
+	}
+
+	owner, err := highlightOwner(highlight.UserID, highlight.HighlightId)
+	if err != nil {
+		http.Error(w, "Error retrieving highlight", http.StatusInternalServerError)
+		return
+	}
+	if !owner {
+		http.Error(w, "Invalid highlight id", http.StatusBadRequest)
+		return
+	}
+
+	if err = validateHighlight(highlight.UserID, highlight.Title, highlight.StoryIds); err != nil {
+		http.Error(w, fmt.Sprint(err), http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "Error updating highlight", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	if highlight.Title != nil {
+		_, err = tx.Exec("UPDATE highlights SET title=$1 WHERE highlight_id=$2", *highlight.Title, highlight.HighlightId)
+		if err != nil {
+			http.Error(w, "Error updating highlight title", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if highlight.StoryIds != nil {
+		if err = setHighlightStories(tx, highlight.HighlightId, highlight.StoryIds); err != nil {
+			http.Error(w, "Error updating highlight stories", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		http.Error(w, "Error updating highlight", http.StatusInternalServerError)
+		return
+	}
+
+	fmt.Fprintln(w, "Highlight updated successfully")
+}
+
+func ReorderHighlight(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var highlight models.HighlightInfo
+	err := json.NewDecoder(r.Body).Decode(&highlight)
+	if err != nil {
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if highlight.UserID <= 0 || highlight.HighlightId <= 0 || len(highlight.StoryIds) == 0 {
+		http.Error(w, "Invalid ids or missing fields", http.StatusBadRequest)
+		return
+	}
+
+	owner, err := highlightOwner(highlight.UserID, highlight.HighlightId)
+	if err != nil {
+		http.Error(w, "Error retrieving highlight", http.StatusInternalServerError)
+		return
+	}
+	if !owner {
+		http.Error(w, "Invalid highlight id", http.StatusBadRequest)
+		return
+	}
+
+	//new order must contain exactly the stories already in the highlight
+	var count int
+	var matched int
+	err = db.DB.QueryRow("SELECT COUNT(story_id),COUNT(story_id) FILTER (WHERE story_id=ANY($2)) FROM highlight_stories WHERE highlight_id=$1", highlight.HighlightId, pq.Array(highlight.StoryIds)).Scan(&count, &matched)
+	if err != nil {
+		http.Error(w, "Error retrieving highlight stories", http.StatusInternalServerError)
+		return
+	}
+	if count != len(highlight.StoryIds) || matched != count {
+		http.Error(w, "Story ids should match the stories of the highlight", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		http.Error(w, "Error reordering highlight", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	if err = setHighlightStories(tx, highlight.HighlightId, highlight.StoryIds); err != nil {
+		http.Error(w, "Error reordering highlight", http.StatusInternalServerError)
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		http.Error(w, "Error reordering highlight", http.StatusInternalServerError)
+		return
+	}
+
+	fmt.Fprintln(w, "Highlight reordered successfully")
+}
+
+func DeleteHighlight(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var highlight models.HighlightInfo
+	err := json.NewDecoder(r.Body).Decode(&highlight)
+	if err != nil {
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if highlight.UserID <= 0 || highlight.HighlightId <= 0 {
+		http.Error(w, "Invalid ids or missing fields", http.StatusBadRequest)
+		return
+	}
+
+	var coverPath string
+	err = db.DB.QueryRow("DELETE FROM highlights WHERE highlight_id=$1 AND user_id=$2 RETURNING cover_path", highlight.HighlightId, highlight.UserID).Scan(&coverPath)
+	if err != nil {
+		http.Error(w, "Invalid highlight id", http.StatusBadRequest)
+		return
+	}
+
+	if coverPath != "" {
+		os.Remove("./" + coverPath)
+	}
+
+	fmt.Fprintln(w, "Highlight deleted successfully")
+}
+
+func UploadHighlightCover(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 8*MB)
+	err := r.ParseMultipartForm(8 * MB)
+	if err != nil {
+		http.Error(w, "Error parsing multipart form data or file size may be out of bound", http.StatusBadRequest)
+		return
+	}
+
+	jsonData := r.FormValue("highlightInfo")
+
+	var highlight models.HighlightInfo
+	err = json.Unmarshal([]byte(jsonData), &highlight)
+	if err != nil {
+		http.Error(w, "Error unmarshalling JSON data", http.StatusBadRequest)
+		return
+	}
+
+	owner, err := highlightOwner(highlight.UserID, highlight.HighlightId)
+	if err != nil {
+		http.Error(w, "Error retrieving highlight", http.StatusInternalServerError)
+		return
+	}
+	if !owner {
+		http.Error(w, "Invalid highlight id", http.StatusBadRequest)
+		return
+	}
+
+	file, fileHeader, err := r.FormFile("cover")
+	if err != nil {
+		http.Error(w, "Missing formfile", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	//check for file allowed file format
+	match, _ := regexp.MatchString("^.*\\.(jpg|JPG|png|PNG|JPEG|jpeg|bmp|BMP)$", fileHeader.Filename)
+	if !match {
+		fmt.Fprintln(w, "Only JPG,JPEG,PNG,BMP formats are allowed for upload")
+		return
+	}
+
+	//get cleaned file name
+	s := regexp.MustCompile(`\s+`).ReplaceAllString(fileHeader.Filename, "")
+	time := fmt.Sprintf("%v", time.Now())
+	s = regexp.MustCompile(`\s+`).ReplaceAllString(time, "") + s
+
+	dst, err := os.Create(filepath.Join("./highlights", s))
+	if err != nil {
+		http.Error(w, "Unable to create a file", http.StatusInternalServerError)
+		return
+	}
+	defer dst.Close()
+
+	_, err = io.Copy(dst, file)
+	if err != nil {
+		http.Error(w, "Unable to write file", http.StatusInternalServerError)
+		return
+	}
+	coverPath := "highlights/" + s
+
+	var oldCover string
+	err = db.DB.QueryRow("SELECT cover_path FROM highlights WHERE highlight_id=$1", highlight.HighlightId).Scan(&oldCover)
+	if err != nil {
+		os.Remove("./" + coverPath)
+		http.Error(w, "Error retrieving highlight", http.StatusInternalServerError)
+		return
+	}
+
+	_, err = db.DB.Exec("UPDATE highlights SET cover_path=$1 WHERE highlight_id=$2", coverPath, highlight.HighlightId)
+	if err != nil {
+		os.Remove("./" + coverPath)
+		http.Error(w, "Error updating highlight cover", http.StatusInternalServerError)
+		return
+	}
+
+	if oldCover != "" {
+		os.Remove("./" + oldCover)
+	}
+
+	var cover models.GetProfilePicURL
+	cover.PicURL = "http://localhost:3000/download/" + coverPath
+	json.NewEncoder(w).Encode(cover)
+}
+
+func DownloadHighlightCover(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	_, file := path.Split(r.URL.Path)
+
+	imagedata, err := ioutil.ReadFile("./highlights/" + file)
+	if err != nil {
+		http.Error(w, "Couldn't read the file", http.StatusInternalServerError)
+		return
+	}
+
+	contentType := models.GetExtension(strings.ToLower(filepath.Ext(file)))
+	if contentType == "" {
+		http.Error(w, "Unsupported file format", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+
+	_, err = w.Write(imagedata)
+	if err != nil {
+		http.Error(w, "failed to write image data to response", http.StatusInternalServerError)
+		return
+	}
+}
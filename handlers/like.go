@@ -3,52 +3,153 @@ package handlers
 import (
 	"backend/db"
 	"backend/models"
+	"backend/src"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"net/http"
 )
 
+// returns the reaction count of a post in total and per emoji, nil when the owner has hidden it from the viewer
+func visibleReactions(postId, viewerId int64) (*int64, map[string]int64, error) {
+	var ownerId int64
+	var hideLike bool
+	err := db.DB.QueryRow("SELECT user_id,hide_like FROM posts WHERE post_id=$1", postId).Scan(&ownerId, &hideLike)
+	if err != nil {
+		return nil, nil, err
+	}
+	if hideLike && ownerId != viewerId {
+		return nil, nil, nil
+	}
+
+	row, err := db.DB.Query("SELECT reaction,COUNT(user_id) FROM likes WHERE post_id=$1 GROUP BY reaction", postId)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer row.Close()
+
+	var total int64
+	reactions := make(map[string]int64)
+	for row.Next() {
+		var reaction string
+		var count int64
+		err = row.Scan(&reaction, &count)
+		if err != nil {
+			return nil, nil, err
+		}
+		reactions[reaction] = count
+		total += count
+	}
+	return &total, reactions, nil
+}
+
+// returns the reaction of a user on a post, empty when they haven't reacted
+func postReaction(postId, userId int64) (string, error) {
+	var reaction string
+	err := db.DB.QueryRow("SELECT reaction FROM likes WHERE post_id=$1 AND user_id=$2", postId, userId).Scan(&reaction)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return reaction, err
+}
+
+// decodes a like request and checks the user can see the post
+func likeRequest(w http.ResponseWriter, r *http.Request) (models.PostReaction, bool) {
+	var requestBody models.PostReaction
+	err := json.NewDecoder(r.Body).Decode(&requestBody)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return requestBody, false
+	}
+	//validate for proper userId and PostId
+	if requestBody.PostId <= 0 || requestBody.UserID <= 0 {
+		http.Error(w, "Invalid userId or PostId/missing fields", http.StatusBadRequest)
+		return requestBody, false
+	}
+
+	var ownerId int64
+	err = db.DB.QueryRow("SELECT user_id FROM posts WHERE post_id=$1 AND complete_post=$2", requestBody.PostId, true).Scan(&ownerId)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Invalid post id", http.StatusBadRequest)
+		return requestBody, false
+	}
+	if err != nil {
+		http.Error(w, "Error retrieving post", http.StatusInternalServerError)
+		return requestBody, false
+	}
+
+	visible, err := canViewProfile(requestBody.UserID, ownerId)
+	if err != nil {
+		http.Error(w, "Error retrieving account", http.StatusInternalServerError)
+		return requestBody, false
+	}
+	if !visible {
+		http.Error(w, "This account is private", http.StatusForbidden)
+		return requestBody, false
+	}
+	return requestBody, true
+}
+
+// reacts to a post, a like when no reaction is given; reacting again replaces the reaction
 func LikePosts(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	var requestBody models.LikePost
-	err := json.NewDecoder(r.Body).Decode(&requestBody)
-	if err != nil {
-		http.Error(w, "Invalid request body", http.StatusMethodNotAllowed)
+	requestBody, ok := likeRequest(w, r)
+	if !ok {
 		return
 	}
-	//validate for proper userId and PostId
-	if requestBody.PostId == 0 || requestBody.UserID == 0 {
-		fmt.Fprintln(w, "Invalid userId or PostId/missing fields")
+
+	if requestBody.Reaction == "" {
+		requestBody.Reaction = models.PostReactionLike
+	}
+	reaction, ok := src.PostReaction(requestBody.Reaction)
+	if !ok {
+		http.Error(w, "Unsupported reaction", http.StatusBadRequest)
 		return
 	}
+	requestBody.Reaction = reaction
 
-	getUserName := `SELECT user_name FROM users WHERE user_id=$1`
-	var userName string
-	err = db.DB.QueryRow(getUserName, requestBody.UserID).Scan(&userName)
+	_, err := db.DB.Exec("INSERT INTO likes(post_id,user_id,reaction) VALUES($1,$2,$3) ON CONFLICT (post_id,user_id) DO UPDATE SET reaction=EXCLUDED.reaction", requestBody.PostId, requestBody.UserID, requestBody.Reaction)
 	if err != nil {
-		panic(err)
+		http.Error(w, "Error liking post", http.StatusInternalServerError)
+		return
 	}
 
-	insertLike := `INSERT INTO likes(post_id,user_name) VALUES($1,$2)`
-	_, err = db.DB.Query(insertLike, requestBody.PostId, userName)
+	var likes models.TotalLikes
+	likes.TotalLikes, likes.Reactions, err = visibleReactions(requestBody.PostId, requestBody.UserID)
 	if err != nil {
+		http.Error(w, "Error retrieving likes count", http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(likes)
+}
 
-		_, err := db.DB.Query("DELETE FROM likes WHERE user_name=$1", userName)
-		if err != nil {
-			panic(err)
-		}
+// removes the user's reaction from a post, unliking again has no effect
+func UnlikePost(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	requestBody, ok := likeRequest(w, r)
+	if !ok {
+		return
+	}
 
+	_, err := db.DB.Exec("DELETE FROM likes WHERE post_id=$1 AND user_id=$2", requestBody.PostId, requestBody.UserID)
+	if err != nil {
+		http.Error(w, "Error unliking post", http.StatusInternalServerError)
+		return
 	}
 
-	getTotalLikes := `SELECT COUNT(user_name) FROM likes WHERE post_id=$1`
 	var likes models.TotalLikes
-	err = db.DB.QueryRow(getTotalLikes, requestBody.PostId).Scan(&likes.TotalLikes)
+	likes.TotalLikes, likes.Reactions, err = visibleReactions(requestBody.PostId, requestBody.UserID)
 	if err != nil {
-		panic(err)
+		http.Error(w, "Error retrieving likes count", http.StatusInternalServerError)
+		return
 	}
 	json.NewEncoder(w).Encode(likes)
 }
@@ -76,7 +177,7 @@ func HideLikeCount(w http.ResponseWriter, r *http.Request) {
 		panic(err)
 	}
 
-	_, err = db.DB.Query("UPDATE posts SET hide_like=$1 WHERE user_id=$2", true, commentoff.UserID)
+	_, err = db.DB.Exec("UPDATE posts SET hide_like=$1 WHERE post_id=$2", true, commentoff.PostId)
 	if err != nil {
 		panic(err)
 	}
@@ -107,10 +208,93 @@ func ShowLikeCount(w http.ResponseWriter, r *http.Request) {
 		panic(err)
 	}
 
-	_, err = db.DB.Query("UPDATE posts SET hide_like=$1 WHERE user_id=$2", false, commentoff.UserID)
+	_, err = db.DB.Exec("UPDATE posts SET hide_like=$1 WHERE post_id=$2", false, commentoff.PostId)
 	if err != nil {
 		panic(err)
 	}
 	fmt.Fprintln(w, "updated hide_likes=false")
 
 }
+
+func PostLikers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request models.PostLikersRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if request.UserID <= 0 || request.PostId <= 0 {
+		http.Error(w, "Invalid ids or missing fields", http.StatusBadRequest)
+		return
+	}
+
+	var ownerId int64
+	var hideLike bool
+	err = db.DB.QueryRow("SELECT user_id,hide_like FROM posts WHERE post_id=$1 AND complete_post=$2", request.PostId, true).Scan(&ownerId, &hideLike)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Invalid post id", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error retrieving post", http.StatusInternalServerError)
+		return
+	}
+
+	//likers of a post with hidden likes are visible only to its owner
+	if hideLike && ownerId != request.UserID {
+		http.Error(w, "Likes of this post are hidden", http.StatusForbidden)
+		return
+	}
+
+	visible, err := canViewProfile(request.UserID, ownerId)
+	if err != nil {
+		http.Error(w, "Error retrieving account", http.StatusInternalServerError)
+		return
+	}
+	if !visible {
+		http.Error(w, "This account is private", http.StatusForbidden)
+		return
+	}
+
+	if request.Reaction != "" {
+		reaction, ok := src.PostReaction(request.Reaction)
+		if !ok {
+			http.Error(w, "Unsupported reaction", http.StatusBadRequest)
+			return
+		}
+		request.Reaction = reaction
+	}
+
+	//all reactions unless filtered by one emoji
+	limit, offset := pageBounds(request.Page, request.Limit)
+	row, err := db.DB.Query(`SELECT u.user_id,u.user_name,u.name,u.display_pic,l.reaction,
+		EXISTS(SELECT 1 FROM follower f WHERE f.user_id=$2 AND f.follower_id=u.user_id AND f.accepted=$3)
+		FROM likes l JOIN users u ON u.user_id=l.user_id
+		WHERE l.post_id=$1 AND ($6='' OR l.reaction=$6)
+		AND NOT EXISTS(SELECT 1 FROM blocks b WHERE (b.user_id=$2 AND b.blocked_id=l.user_id) OR (b.user_id=l.user_id AND b.blocked_id=$2))
+		ORDER BY l.liked_on DESC,l.user_id LIMIT $4 OFFSET $5`, request.PostId, request.UserID, true, limit, offset, request.Reaction)
+	if err != nil {
+		http.Error(w, "Error retrieving likes", http.StatusInternalServerError)
+		return
+	}
+	defer row.Close()
+
+	likers := []models.Liker{}
+	for row.Next() {
+		var liker models.Liker
+		err = row.Scan(&liker.UserID, &liker.UserName, &liker.Name, &liker.ProfilePic, &liker.Reaction, &liker.Following)
+		if err != nil {
+			http.Error(w, "Error reading likes", http.StatusInternalServerError)
+			return
+		}
+		liker.ProfilePic = "http://localhost:3000/getProfilePic/" + liker.ProfilePic
+		likers = append(likers, liker)
+	}
+	json.NewEncoder(w).Encode(likers)
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"backend/models"
+	"database/sql/driver"
+	"net/http"
+	"testing"
+)
+
+func TestLikePostsUpsertsNormalizedReaction(t *testing.T) {
+	t.Setenv("POST_REACTIONS", "")
+	expectQueries(t,
+		fakeQuery{sql: "SELECT user_id FROM posts WHERE post_id=$1 AND complete_post=$2", columns: []string{"user_id"}, rows: [][]driver.Value{{int64(2)}}},
+		fakeQuery{sql: "FROM blocks", columns: []string{"blocked"}, rows: [][]driver.Value{{false}}},
+		fakeQuery{sql: "SELECT private FROM users", columns: []string{"private"}, rows: [][]driver.Value{{false}}},
+		//a bare heart is stored in the same form as the default like
+		fakeQuery{sql: "ON CONFLICT (post_id,user_id) DO UPDATE SET reaction=EXCLUDED.reaction", args: []driver.Value{int64(1), int64(7), models.PostReactionLike}, affected: 1},
+		fakeQuery{sql: "SELECT user_id,hide_like FROM posts", columns: []string{"user_id", "hide_like"}, rows: [][]driver.Value{{int64(2), false}}},
+		fakeQuery{sql: "SELECT reaction,COUNT(user_id) FROM likes", columns: []string{"reaction", "count"}, rows: [][]driver.Value{{models.PostReactionLike, int64(3)}}},
+	)
+
+	w := serve(LikePosts, http.MethodPost, models.PostReaction{PostId: 1, UserID: 7, Reaction: "❤"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body)
+	}
+}
+
+func TestLikePostsRejectsUnsupportedReaction(t *testing.T) {
+	t.Setenv("POST_REACTIONS", "")
+	expectQueries(t,
+		fakeQuery{sql: "SELECT user_id FROM posts WHERE post_id=$1 AND complete_post=$2", columns: []string{"user_id"}, rows: [][]driver.Value{{int64(2)}}},
+		fakeQuery{sql: "FROM blocks", columns: []string{"blocked"}, rows: [][]driver.Value{{false}}},
+		fakeQuery{sql: "SELECT private FROM users", columns: []string{"private"}, rows: [][]driver.Value{{false}}},
+	)
+
+	w := serve(LikePosts, http.MethodPost, models.PostReaction{PostId: 1, UserID: 7, Reaction: "🍍"})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d, got %d: %s", http.StatusBadRequest, w.Code, w.Body)
+	}
+}
+
+func TestLikePostsRejectsPrivateAccount(t *testing.T) {
+	expectQueries(t,
+		fakeQuery{sql: "SELECT user_id FROM posts WHERE post_id=$1 AND complete_post=$2", columns: []string{"user_id"}, rows: [][]driver.Value{{int64(2)}}},
+		fakeQuery{sql: "FROM blocks", columns: []string{"blocked"}, rows: [][]driver.Value{{false}}},
+		fakeQuery{sql: "SELECT private FROM users", columns: []string{"private"}, rows: [][]driver.Value{{true}}},
+		fakeQuery{sql: "FROM follower WHERE user_id=$1 AND follower_id=$2 AND accepted=$3", columns: []string{"exists"}, rows: [][]driver.Value{{false}}},
+	)
+
+	w := serve(LikePosts, http.MethodPost, models.PostReaction{PostId: 1, UserID: 7})
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected %d, got %d: %s", http.StatusForbidden, w.Code, w.Body)
+	}
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"backend/db"
+	"backend/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+func MuteUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var mute models.Mute
+	err := json.NewDecoder(r.Body).Decode(&mute)
+	if err != nil {
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if mute.UserID <= 0 || mute.MutedId <= 0 || mute.UserID == mute.MutedId || mute.MutePosts == nil || mute.MuteStories == nil {
+		http.Error(w, "Invalid ids or missing fields", http.StatusBadRequest)
+		return
+	}
+
+	//unmuting both removes the mute
+	if !*mute.MutePosts && !*mute.MuteStories {
+		_, err = db.DB.Exec("DELETE FROM mutes WHERE user_id=$1 AND muted_id=$2", mute.UserID, mute.MutedId)
+		if err != nil {
+			http.Error(w, "Error unmuting user", http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintln(w, "User unmuted")
+		return
+	}
+
+	var idexists bool
+	err = db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE user_id=$1)", mute.MutedId).Scan(&idexists)
+	if err != nil {
+		http.Error(w, "Invalid user-id", http.StatusInternalServerError)
+		return
+	}
+	if !idexists {
+		http.Error(w, "No user exists with this user-id", http.StatusBadRequest)
+		return
+	}
+
+	_, err = db.DB.Exec(`INSERT INTO mutes(user_id,muted_id,mute_posts,mute_stories) VALUES($1,$2,$3,$4)
+		ON CONFLICT (user_id,muted_id) DO UPDATE SET mute_posts=EXCLUDED.mute_posts,mute_stories=EXCLUDED.mute_stories`, mute.UserID, mute.MutedId, *mute.MutePosts, *mute.MuteStories)
+	if err != nil {
+		http.Error(w, "Error muting user", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintln(w, "Mute settings updated")
+}
+
+func MutedUsers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var userId models.UserID
+	err := json.NewDecoder(r.Body).Decode(&userId)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if userId.UserId <= 0 {
+		http.Error(w, "Invalid user id or missing field", http.StatusBadRequest)
+		return
+	}
+
+	row, err := db.DB.Query(`SELECT u.user_id,u.user_name,u.name,u.display_pic,m.mute_posts,m.mute_stories FROM mutes m
+		JOIN users u ON u.user_id=m.muted_id
+		WHERE m.user_id=$1 ORDER BY m.muted_on DESC`, userId.UserId)
+	if err != nil {
+		http.Error(w, "Error retrieving muted users", http.StatusInternalServerError)
+		return
+	}
+	defer row.Close()
+
+	var muted []models.MutedAccount
+	for row.Next() {
+		var acc models.MutedAccount
+		err = row.Scan(&acc.UserID, &acc.UserName, &acc.Name, &acc.ProfilePic, &acc.MutePosts, &acc.MuteStories)
+		if err != nil {
+			http.Error(w, "Error reading muted users", http.StatusInternalServerError)
+			return
+		}
+		acc.ProfilePic = "http://localhost:3000/getProfilePic/" + acc.ProfilePic
+		muted = append(muted, acc)
+	}
+
+	json.NewEncoder(w).Encode(muted)
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"backend/db"
+	"backend/models"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+)
+
+// delivers a notification about a story to a user
+func notifyStory(userId, actorId int64, notificationType string, storyId int64) error {
+	if userId == actorId {
+		return nil
+	}
+	_, err := db.DB.Exec("INSERT INTO notifications(user_id,actor_id,notification_type,story_id) VALUES($1,$2,$3,$4)", userId, actorId, notificationType, storyId)
+	return err
+}
+
+func GetNotifications(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var userId models.UserID
+	err := json.NewDecoder(r.Body).Decode(&userId)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if userId.UserId <= 0 {
+		http.Error(w, "Invalid user id or missing field", http.StatusBadRequest)
+		return
+	}
+
+	//a mention can be reshared once while the story exists
+	row, err := db.DB.Query(`SELECT n.notification_id,n.notification_type,n.actor_id,u.user_name,u.display_pic,n.story_id,n.seen,n.created_on,
+		n.notification_type=$2 AND n.story_id IS NOT NULL AND NOT EXISTS(SELECT 1 FROM stories s WHERE s.user_id=$1 AND s.reshared_from=n.story_id)
+		FROM notifications n JOIN users u ON u.user_id=n.actor_id
+		WHERE n.user_id=$1 ORDER BY n.created_on DESC`, userId.UserId, models.NotificationStoryMention)
+	if err != nil {
+		http.Error(w, "Error retrieving notifications", http.StatusInternalServerError)
+		return
+	}
+	defer row.Close()
+
+	var notifications []models.Notification
+	for row.Next() {
+		var notification models.Notification
+		var storyId sql.NullInt64
+		err = row.Scan(&notification.NotificationId, &notification.Type, &notification.ActorId, &notification.ActorUserName, &notification.ActorProfilePic, &storyId, &notification.Seen, &notification.CreatedOn, &notification.CanReshare)
+		if err != nil {
+			http.Error(w, "Error reading notifications", http.StatusInternalServerError)
+			return
+		}
+		notification.StoryId = storyId.Int64
+		notification.ActorProfilePic = "http://localhost:3000/getProfilePic/" + notification.ActorProfilePic
+		notifications = append(notifications, notification)
+	}
+
+	_, err = db.DB.Exec("UPDATE notifications SET seen=$1 WHERE user_id=$2 AND seen=$3", true, userId.UserId, false)
+	if err != nil {
+		http.Error(w, "Error updating notifications", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(notifications)
+}
@@ -116,6 +116,17 @@ func PostMedia(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	//users across a block can't be tagged
+	isBlocked, err := blockedAny(*postInfo.UserID, postInfo.TaggedIds)
+	if err != nil {
+		http.Error(w, "Error checking tagged users", http.StatusInternalServerError)
+		return
+	}
+	if isBlocked {
+		http.Error(w, "You can't tag one of these users", http.StatusForbidden)
+		return
+	}
+
 	//check for existance of hash ids
 	for _, id := range postInfo.HashtagIds {
 		err = db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM hashtags WHERE hash_id=$1)", id).Scan(&idexists)
@@ -146,16 +157,34 @@ func PostMedia(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	//comment audience falls back to the account default
+	if postInfo.CommentAudience == "" {
+		err = db.DB.QueryRow("SELECT comment_audience FROM users WHERE user_id=$1", postInfo.UserID).Scan(&postInfo.CommentAudience)
+		if err != nil {
+			http.Error(w, "Error retrieving default comment audience", http.StatusInternalServerError)
+			return
+		}
+	}
+	if err = validCommentAudience(postInfo.CommentAudience); err != nil {
+		http.Error(w, fmt.Sprint(err), http.StatusBadRequest)
+		return
+	}
+	if *postInfo.TurnOffComments {
+		postInfo.CommentAudience = models.CommentAudienceOff
+	}
+	*postInfo.TurnOffComments = postInfo.CommentAudience == models.CommentAudienceOff
+
 	var postId models.PostId
-	insertPostInfo := `INSERT INTO posts(user_id,poat_caption,location,hide_like,hide_comments) VALUES($1,$2,$3,$4,$5) RETURNING post_id`
-	err = db.DB.QueryRow(insertPostInfo, postInfo.UserID, postInfo.PostCaption, postInfo.Location, postInfo.HideLikeCount, postInfo.TurnOffComments).Scan(&postId.PostId)
+	insertPostInfo := `INSERT INTO posts(user_id,poat_caption,location,hide_like,hide_comments,comment_audience) VALUES($1,$2,$3,$4,$5,$6) RETURNING post_id`
+	err = db.DB.QueryRow(insertPostInfo, postInfo.UserID, postInfo.PostCaption, postInfo.Location, postInfo.HideLikeCount, postInfo.TurnOffComments, postInfo.CommentAudience).Scan(&postId.PostId)
 	if err != nil {
 		panic(err)
 	}
 
 	//update tags
 	for _, tagid := range postInfo.TaggedIds {
-		_, err = db.DB.Query("INSERT INTO tagged_users(post_id,tagged_ids) VALUES($1,$2)", postId.PostId, tagid)
+		//tags of users who restricted the author wait for approval
+		_, err = db.DB.Exec("INSERT INTO tagged_users(post_id,tagged_ids,approved) VALUES($1,$2,NOT EXISTS(SELECT 1 FROM restricts WHERE user_id=$2 AND restricted_id=$3))", postId.PostId, tagid, postInfo.UserID)
 		if err != nil {
 			db.DB.Query("DELETE FROM posts WHERE post_id=$1", postId.PostId)
 			http.Error(w, "Error inserting tagged users", http.StatusInternalServerError)
@@ -314,7 +343,7 @@ func AllPosts(w http.ResponseWriter, r *http.Request) {
 	}
 	var userPosts []models.UsersPost
 
-	var userId models.UserID
+	var userId models.ProfileView
 	// userId.UserId = 1
 	err := json.NewDecoder(r.Body).Decode(&userId)
 	if err != nil {
@@ -326,7 +355,17 @@ func AllPosts(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	getPosts := `SELECT post_id,post_path,poat_caption,location,hide_like,hide_comments,posted_on FROM posts WHERE user_id=$1 ORDER BY posted_on DESC`
+	isBlocked, err := blockedBetween(userId.ViewerId, userId.UserId)
+	if err != nil {
+		http.Error(w, "Error retrieving account", http.StatusInternalServerError)
+		return
+	}
+	if isBlocked {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	getPosts := `SELECT post_id,post_path,poat_caption,location,hide_like,hide_comments,comment_audience,posted_on FROM posts WHERE user_id=$1 ORDER BY posted_on DESC`
 	row, err := db.DB.Query(getPosts, userId.UserId)
 	if err != nil {
 		panic(err)
@@ -344,21 +383,23 @@ func AllPosts(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		var postURLstr string
-		err = row.Scan(&userPost.PostId, &postURLstr, &userPost.PostCaption, &userPost.AttachedLocation, &userPost.HideLikeCount, &userPost.HideLikeCount, &userPost.PostedOn)
+		err = row.Scan(&userPost.PostId, &postURLstr, &userPost.PostCaption, &userPost.AttachedLocation, &userPost.HideLikeCount, &userPost.TurnOffComments, &userPost.CommentAudience, &userPost.PostedOn)
 		if err != nil {
 			panic(err)
 		}
 
 		//get like status of present user
-		err = db.DB.QueryRow("SELECT EXISTS(SELECT user_name FROM likes WHERE post_id=$1 AND user_name=$2)", userPost.PostId, userPost.UserName).Scan(&userPost.LikeStatus)
+		userPost.Reaction, err = postReaction(userPost.PostId, userId.ViewerId)
 		if err != nil {
 			panic(err)
 		}
+		userPost.LikeStatus = userPost.Reaction != ""
 
-		//get count of likes
-		err = db.DB.QueryRow("SELECT COUNT(user_name) FROM likes WHERE post_id=$1", userPost.PostId).Scan(&userPost.Likes)
+		//get count of likes, hidden from viewers when the owner turned it off
+		userPost.Likes, userPost.Reactions, err = visibleReactions(userPost.PostId, userId.ViewerId)
 		if err != nil {
-			panic(err)
+			http.Error(w, "Error retrieving like count", http.StatusInternalServerError)
+			return
 		}
 		postURL := strings.Split(postURLstr, ",")
 		for _, url := range postURL {
@@ -367,11 +408,10 @@ func AllPosts(w http.ResponseWriter, r *http.Request) {
 
 		}
 
-		err = db.DB.QueryRow("SELECT user_id,post_id FROM savedposts WHERE user_id=$1.post_id=$2", userPost.UserID, userPost.PostId).Scan(&userPost.UserID, &userPost.PostId)
+		err = db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM savedposts WHERE user_id=$1 AND post_id=$2)", userId.ViewerId, userPost.PostId).Scan(&userPost.SavedStatus)
 		if err != nil {
-			userPost.SavedStatus = false
-		} else {
-			userPost.SavedStatus = true
+			http.Error(w, "Error retrieving saved status", http.StatusInternalServerError)
+			return
 		}
 
 		userPost.UserID = userId.UserId
@@ -380,3 +420,76 @@ func AllPosts(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(userPosts)
 
 }
+
+func HomeFeed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request models.FeedRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if request.UserID <= 0 {
+		http.Error(w, "Invalid user id or missing field", http.StatusBadRequest)
+		return
+	}
+
+	//own posts and posts of accepted followees, leaving out accounts whose posts are muted,
+	//with the viewer's reaction, the reaction counts and the saved status of each post
+	limit, offset := pageBounds(request.Page, request.Limit)
+	row, err := db.DB.Query(`SELECT p.post_id,p.user_id,u.user_name,u.display_pic,p.post_path,p.poat_caption,p.location,p.hide_like,p.hide_comments,p.comment_audience,p.posted_on,
+		COALESCE((SELECT l.reaction FROM likes l WHERE l.post_id=p.post_id AND l.user_id=$1),''),
+		(SELECT json_object_agg(r.reaction,r.count) FROM (SELECT reaction,COUNT(user_id) AS count FROM likes WHERE post_id=p.post_id GROUP BY reaction) r),
+		EXISTS(SELECT 1 FROM savedposts s WHERE s.user_id=$1 AND s.post_id=p.post_id)
+		FROM posts p JOIN users u ON u.user_id=p.user_id
+		WHERE p.complete_post=$2
+		AND (p.user_id=$1 OR p.user_id IN (SELECT follower_id FROM follower WHERE user_id=$1 AND accepted=$2))
+		AND NOT EXISTS(SELECT 1 FROM mutes m WHERE m.user_id=$1 AND m.muted_id=p.user_id AND m.mute_posts=$2)
+		ORDER BY p.posted_on DESC,p.post_id DESC LIMIT $3 OFFSET $4`, request.UserID, true, limit, offset)
+	if err != nil {
+		http.Error(w, "Error retrieving feed", http.StatusInternalServerError)
+		return
+	}
+	defer row.Close()
+
+	feed := []models.UsersPost{}
+	for row.Next() {
+		var post models.UsersPost
+		var dpURL, postURLstr string
+		var reactions []byte
+		err = row.Scan(&post.PostId, &post.UserID, &post.UserName, &dpURL, &postURLstr, &post.PostCaption, &post.AttachedLocation, &post.HideLikeCount, &post.TurnOffComments, &post.CommentAudience, &post.PostedOn,
+			&post.Reaction, &reactions, &post.SavedStatus)
+		if err != nil {
+			http.Error(w, "Error reading feed", http.StatusInternalServerError)
+			return
+		}
+		post.LikeStatus = post.Reaction != ""
+		post.UserProfilePicURL = "http://localhost:3000/getProfilePic/" + dpURL
+		for _, url := range strings.Split(postURLstr, ",") {
+			post.PostURL = append(post.PostURL, "http://localhost:3000/download/"+url)
+		}
+
+		//reaction counts are hidden from viewers when the owner turned them off
+		if !post.HideLikeCount || post.UserID == request.UserID {
+			post.Reactions = make(map[string]int64)
+			if reactions != nil {
+				err = json.Unmarshal(reactions, &post.Reactions)
+				if err != nil {
+					http.Error(w, "Error reading likes count", http.StatusInternalServerError)
+					return
+				}
+			}
+			var total int64
+			for _, count := range post.Reactions {
+				total += count
+			}
+			post.Likes = &total
+		}
+		feed = append(feed, post)
+	}
+	json.NewEncoder(w).Encode(feed)
+}
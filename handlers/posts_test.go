@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"backend/models"
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestHomeFeedHidesMutedAndHiddenCounts(t *testing.T) {
+	columns := []string{"post_id", "user_id", "user_name", "display_pic", "post_path", "poat_caption", "location", "hide_like", "hide_comments", "comment_audience", "posted_on",
+		"reaction", "reactions", "saved"}
+	expectQueries(t,
+		fakeQuery{sql: "AND NOT EXISTS(SELECT 1 FROM mutes m WHERE m.user_id=$1 AND m.muted_id=p.user_id AND m.mute_posts=$2)", args: []driver.Value{int64(7), true, int64(10), int64(0)},
+			columns: columns, rows: [][]driver.Value{
+				{int64(3), int64(2), "two", "2.jpg", "posts/3.jpg", "", "", true, false, models.CommentAudienceEveryone, "2026-10-19", models.PostReactionLike, []byte(`{"❤️":4}`), true},
+				{int64(1), int64(7), "seven", "7.jpg", "posts/1.jpg", "", "", true, false, models.CommentAudienceEveryone, "2026-10-18", "", []byte(`{"❤️":2,"😂":1}`), false},
+				{int64(4), int64(5), "five", "5.jpg", "posts/4.jpg", "", "", false, false, models.CommentAudienceEveryone, "2026-10-17", "", nil, false},
+			}},
+	)
+
+	w := serve(HomeFeed, http.MethodGet, models.FeedRequest{UserID: 7, Limit: 10})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body)
+	}
+
+	var feed []models.UsersPost
+	if err := json.NewDecoder(w.Body).Decode(&feed); err != nil {
+		t.Fatal(err)
+	}
+	if len(feed) != 3 {
+		t.Fatalf("expected 3 posts, got %d", len(feed))
+	}
+	//counts of another account's post with hidden likes are left out
+	if feed[0].Likes != nil || feed[0].Reactions != nil || !feed[0].LikeStatus || !feed[0].SavedStatus {
+		t.Errorf("unexpected first post: %+v", feed[0])
+	}
+	if feed[1].Likes == nil || *feed[1].Likes != 3 || feed[1].Reactions["😂"] != 1 {
+		t.Errorf("expected the owner to see their counts, got %+v", feed[1])
+	}
+	if feed[2].Likes == nil || *feed[2].Likes != 0 || feed[2].LikeStatus {
+		t.Errorf("expected a post without likes to count 0, got %+v", feed[2])
+	}
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"backend/models"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestSetAccountPrivacyAcceptsPendingOnGoingPublic(t *testing.T) {
+	public := false
+	expectQueries(t,
+		fakeQuery{sql: "UPDATE users u SET private=$1", args: []driver.Value{false, int64(2)}, columns: []string{"private"}, rows: [][]driver.Value{{true}}},
+		fakeQuery{sql: "SELECT COUNT(user_id) FROM follower WHERE follower_id=$1 AND accepted=$2", columns: []string{"count"}, rows: [][]driver.Value{{int64(3)}}},
+		fakeQuery{sql: "UPDATE follower SET accepted=$2 WHERE (user_id,follower_id) IN", args: []driver.Value{int64(2), true, false, int64(1000)}, affected: 3},
+	)
+
+	w := serve(SetAccountPrivacy, http.MethodPut, models.PrivacySetting{UserID: 2, Private: &public})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body)
+	}
+	var status models.PrivacyStatus
+	if err := json.NewDecoder(w.Body).Decode(&status); err != nil {
+		t.Fatal(err)
+	}
+	if status.Private || status.AcceptedRequests != 3 || status.AcceptingInBackground {
+		t.Errorf("unexpected status: %+v", status)
+	}
+}
+
+func TestSetAccountPrivacyKeepsRequestsWhenGoingPrivate(t *testing.T) {
+	private := true
+	expectQueries(t,
+		fakeQuery{sql: "UPDATE users u SET private=$1", columns: []string{"private"}, rows: [][]driver.Value{{false}}},
+	)
+
+	w := serve(SetAccountPrivacy, http.MethodPut, models.PrivacySetting{UserID: 2, Private: &private})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body)
+	}
+}
+
+func TestSetAccountPrivacyErrors(t *testing.T) {
+	private := true
+	t.Run("unknown user", func(t *testing.T) {
+		expectQueries(t,
+			fakeQuery{sql: "UPDATE users u SET private=$1", columns: []string{"private"}},
+		)
+		w := serve(SetAccountPrivacy, http.MethodPut, models.PrivacySetting{UserID: 2, Private: &private})
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected %d, got %d: %s", http.StatusBadRequest, w.Code, w.Body)
+		}
+	})
+	t.Run("database error", func(t *testing.T) {
+		expectQueries(t,
+			fakeQuery{sql: "UPDATE users u SET private=$1", err: errors.New("connection reset")},
+		)
+		w := serve(SetAccountPrivacy, http.MethodPut, models.PrivacySetting{UserID: 2, Private: &private})
+		if w.Code != http.StatusInternalServerError {
+			t.Fatalf("expected %d, got %d: %s", http.StatusInternalServerError, w.Code, w.Body)
+		}
+	})
+}
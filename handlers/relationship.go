@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"backend/db"
+	"backend/models"
+	"encoding/json"
+	"net/http"
+)
+
+// mutual followers returned with a relationship
+const mutualFollowersSample = 3
+
+// returns the relationship of viewerId with userId
+func getRelationship(viewerId, userId int64) (models.Relationship, error) {
+	relationship := models.Relationship{UserID: userId, MutualFollowers: []models.Accounts{}}
+
+	err := db.DB.QueryRow(`SELECT
+		EXISTS(SELECT 1 FROM follower WHERE user_id=$1 AND follower_id=$2 AND accepted=$3),
+		EXISTS(SELECT 1 FROM follower WHERE user_id=$2 AND follower_id=$1 AND accepted=$3),
+		EXISTS(SELECT 1 FROM follower WHERE user_id=$1 AND follower_id=$2 AND accepted=$4),
+		EXISTS(SELECT 1 FROM blocks WHERE user_id=$1 AND blocked_id=$2),
+		EXISTS(SELECT 1 FROM mutes WHERE user_id=$1 AND muted_id=$2),
+		(SELECT COUNT(f1.follower_id) FROM follower f1 JOIN follower f2 ON f2.user_id=f1.follower_id AND f2.follower_id=$2 AND f2.accepted=$3
+			WHERE f1.user_id=$1 AND f1.accepted=$3)`, viewerId, userId, true, false).Scan(&relationship.Following, &relationship.FollowedBy, &relationship.Requested, &relationship.Blocked, &relationship.Muted, &relationship.MutualCount)
+	if err != nil {
+		return relationship, err
+	}
+
+	if relationship.MutualCount == 0 {
+		return relationship, nil
+	}
+
+	row, err := db.DB.Query(`SELECT u.user_id,u.user_name,u.name,u.display_pic FROM follower f1
+		JOIN follower f2 ON f2.user_id=f1.follower_id AND f2.follower_id=$2 AND f2.accepted=$3
+		JOIN users u ON u.user_id=f1.follower_id
+		WHERE f1.user_id=$1 AND f1.accepted=$3 ORDER BY u.user_name LIMIT $4`, viewerId, userId, true, mutualFollowersSample)
+	if err != nil {
+		return relationship, err
+	}
+	defer row.Close()
+
+	for row.Next() {
+		var acc models.Accounts
+		err = row.Scan(&acc.UserID, &acc.UserName, &acc.Name, &acc.ProfilePic)
+		if err != nil {
+			return relationship, err
+		}
+		acc.ProfilePic = "http://localhost:3000/getProfilePic/" + acc.ProfilePic
+		relationship.MutualFollowers = append(relationship.MutualFollowers, acc)
+	}
+	return relationship, nil
+}
+
+func GetRelationship(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request models.ProfileView
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if request.UserId <= 0 || request.ViewerId <= 0 || request.UserId == request.ViewerId {
+		http.Error(w, "Invalid ids or missing fields", http.StatusBadRequest)
+		return
+	}
+
+	//the blocked side only learns that the account can't be found
+	blockedBy, err := hasBlocked(request.UserId, request.ViewerId)
+	if err != nil {
+		http.Error(w, "Error retrieving account", http.StatusInternalServerError)
+		return
+	}
+	if blockedBy {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	relationship, err := getRelationship(request.ViewerId, request.UserId)
+	if err != nil {
+		http.Error(w, "Error retrieving relationship", http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(relationship)
+}
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"backend/db"
+	"backend/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+func RestrictUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var restrict models.Restrict
+	err := json.NewDecoder(r.Body).Decode(&restrict)
+	if err != nil {
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if restrict.UserID <= 0 || restrict.RestrictedId <= 0 || restrict.UserID == restrict.RestrictedId {
+		http.Error(w, "Invalid ids or missing fields", http.StatusBadRequest)
+		return
+	}
+
+	var idexists bool
+	err = db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE user_id=$1)", restrict.RestrictedId).Scan(&idexists)
+	if err != nil {
+		http.Error(w, "Invalid user-id", http.StatusInternalServerError)
+		return
+	}
+	if !idexists {
+		http.Error(w, "No user exists with this user-id", http.StatusBadRequest)
+		return
+	}
+
+	_, err = db.DB.Exec("INSERT INTO restricts(user_id,restricted_id) VALUES($1,$2) ON CONFLICT DO NOTHING", restrict.UserID, restrict.RestrictedId)
+	if err != nil {
+		http.Error(w, "Error restricting user", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintln(w, "User restricted")
+}
+
+func UnrestrictUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var restrict models.Restrict
+	err := json.NewDecoder(r.Body).Decode(&restrict)
+	if err != nil {
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if restrict.UserID <= 0 || restrict.RestrictedId <= 0 {
+		http.Error(w, "Invalid ids or missing fields", http.StatusBadRequest)
+		return
+	}
+
+	_, err = db.DB.Exec("DELETE FROM restricts WHERE user_id=$1 AND restricted_id=$2", restrict.UserID, restrict.RestrictedId)
+	if err != nil {
+		http.Error(w, "Error unrestricting user", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintln(w, "User unrestricted")
+}
+
+func RestrictedUsers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var userId models.UserID
+	err := json.NewDecoder(r.Body).Decode(&userId)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if userId.UserId <= 0 {
+		http.Error(w, "Invalid user id or missing field", http.StatusBadRequest)
+		return
+	}
+
+	row, err := db.DB.Query(`SELECT u.user_id,u.user_name,u.name,u.display_pic FROM restricts r
+		JOIN users u ON u.user_id=r.restricted_id
+		WHERE r.user_id=$1 ORDER BY r.restricted_on DESC`, userId.UserId)
+	if err != nil {
+		http.Error(w, "Error retrieving restricted users", http.StatusInternalServerError)
+		return
+	}
+	defer row.Close()
+
+	var restricted []models.Accounts
+	for row.Next() {
+		var acc models.Accounts
+		err = row.Scan(&acc.UserID, &acc.UserName, &acc.Name, &acc.ProfilePic)
+		if err != nil {
+			http.Error(w, "Error reading restricted users", http.StatusInternalServerError)
+			return
+		}
+		acc.ProfilePic = "http://localhost:3000/getProfilePic/" + acc.ProfilePic
+		restricted = append(restricted, acc)
+	}
+
+	json.NewEncoder(w).Encode(restricted)
+}
+
+func PendingTags(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var userId models.UserID
+	err := json.NewDecoder(r.Body).Decode(&userId)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if userId.UserId <= 0 {
+		http.Error(w, "Invalid user id or missing field", http.StatusBadRequest)
+		return
+	}
+
+	//post tags and story tags waiting for approval of the user
+	row, err := db.DB.Query(`SELECT t.post_id,0,u.user_id,u.user_name,u.display_pic FROM tagged_users t
+		JOIN posts p ON p.post_id=t.post_id JOIN users u ON u.user_id=p.user_id
+		WHERE t.tagged_ids=$1 AND t.approved=$2
+		UNION ALL
+		SELECT 0,t.story_id,u.user_id,u.user_name,u.display_pic FROM story_tags t
+		JOIN stories s ON s.story_id=t.story_id JOIN users u ON u.user_id=s.user_id
+		WHERE t.tagged_id=$1 AND t.approved=$2`, userId.UserId, false)
+	if err != nil {
+		http.Error(w, "Error retrieving pending tags", http.StatusInternalServerError)
+		return
+	}
+	defer row.Close()
+
+	var tags []models.PendingTag
+	for row.Next() {
+		var tag models.PendingTag
+		err = row.Scan(&tag.PostId, &tag.StoryId, &tag.TaggedById, &tag.TaggedByName, &tag.TaggedByPicURL)
+		if err != nil {
+			http.Error(w, "Error reading pending tags", http.StatusInternalServerError)
+			return
+		}
+		tag.TaggedByPicURL = "http://localhost:3000/getProfilePic/" + tag.TaggedByPicURL
+		tags = append(tags, tag)
+	}
+
+	json.NewEncoder(w).Encode(tags)
+}
+
+func RespondTag(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var respond models.RespondTag
+	err := json.NewDecoder(r.Body).Decode(&respond)
+	if err != nil {
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if respond.UserID <= 0 || (respond.PostId <= 0) == (respond.StoryId <= 0) {
+		http.Error(w, "Invalid ids, give either a post id or a story id", http.StatusBadRequest)
+		return
+	}
+
+	//approving keeps the tag, otherwise it is removed
+	table, column, taggedColumn, id := "tagged_users", "post_id", "tagged_ids", respond.PostId
+	if respond.StoryId > 0 {
+		table, column, taggedColumn, id = "story_tags", "story_id", "tagged_id", respond.StoryId
+	}
+
+	query := "DELETE FROM " + table + " WHERE " + column + "=$1 AND " + taggedColumn + "=$2 AND approved=$3"
+	args := []interface{}{id, respond.UserID, false}
+	if respond.Approve {
+		query = "UPDATE " + table + " SET approved=$4 WHERE " + column + "=$1 AND " + taggedColumn + "=$2 AND approved=$3"
+		args = append(args, true)
+	}
+
+	result, err := db.DB.Exec(query, args...)
+	if err != nil {
+		http.Error(w, "Error updating tag", http.StatusInternalServerError)
+		return
+	}
+	if updated, _ := result.RowsAffected(); updated == 0 {
+		http.Error(w, "No pending tag for these ids", http.StatusBadRequest)
+		return
+	}
+
+	if !respond.Approve {
+		fmt.Fprintln(w, "Tag removed")
+		return
+	}
+
+	//approved mentions in published stories are notified like any other
+	if respond.StoryId > 0 {
+		var ownerId int64
+		var success bool
+		err = db.DB.QueryRow("SELECT user_id,success FROM stories WHERE story_id=$1", respond.StoryId).Scan(&ownerId, &success)
+		if err != nil {
+			http.Error(w, "Error retrieving story", http.StatusInternalServerError)
+			return
+		}
+		if success {
+			err = notifyStory(respond.UserID, ownerId, models.NotificationStoryMention, respond.StoryId)
+			if err != nil {
+				http.Error(w, "Error notifying mention", http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+	fmt.Fprintln(w, "Tag approved")
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"backend/models"
+	"database/sql/driver"
+	"net/http"
+	"testing"
+)
+
+func TestFilteredCommentHoldsRestrictedCommenter(t *testing.T) {
+	expectQueries(t,
+		fakeQuery{sql: "FROM restricts r WHERE r.user_id=p.user_id AND r.restricted_id=$2", args: []driver.Value{int64(1), int64(7)},
+			columns: []string{"user_id", "restricted"}, rows: [][]driver.Value{{int64(2), true}}},
+	)
+
+	hidden, err := filteredComment(1, 7, "nice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hidden {
+		t.Error("expected the restricted account's comment to be held")
+	}
+}
+
+func TestStoryViewersLeavesOutRestrictingViewers(t *testing.T) {
+	expectQueries(t,
+		fakeQuery{sql: "SELECT EXISTS(SELECT 1 FROM stories WHERE story_id=$1 AND user_id=$2)", columns: []string{"exists"}, rows: [][]driver.Value{{true}}},
+		fakeQuery{sql: "AND NOT EXISTS(SELECT 1 FROM restricts r WHERE r.user_id=s.user_id AND r.restricted_id=$2)", args: []driver.Value{int64(5), int64(2)},
+			columns: []string{"count"}, rows: [][]driver.Value{{int64(1)}}},
+		fakeQuery{sql: "NOT EXISTS(SELECT 1 FROM restricts r WHERE r.user_id=s.user_id AND r.restricted_id=$4)",
+			columns: []string{"user_id", "user_name", "display_pic", "seen_on"}, rows: [][]driver.Value{{int64(3), "three", "3.jpg", "2026-10-19"}}},
+	)
+
+	w := serve(StoryViewers, http.MethodGet, models.StoryViewersRequest{UserID: 2, StoryId: 5})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body)
+	}
+}
+
+func TestRestrictUserRejectsSelf(t *testing.T) {
+	expectQueries(t)
+
+	w := serve(RestrictUser, http.MethodPost, models.Restrict{UserID: 2, RestrictedId: 2})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d, got %d: %s", http.StatusBadRequest, w.Code, w.Body)
+	}
+}
@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"backend/db"
+	"backend/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/lib/pq"
+)
+
+const maxStickersPerStory = 3
+
+// validates sticker fields according to the sticker type
+func validateSticker(sticker *models.StoryStickerInfo) error {
+	if len(sticker.Prompt) > 100 {
+		return errors.New("Sticker prompt should not exceed 100 characters")
+	}
+
+	switch sticker.StickerType {
+	case models.StickerPoll:
+		if len(sticker.Options) < 2 || len(sticker.Options) > 4 {
+			return errors.New("Poll should have 2 to 4 options")
+		}
+		for _, option := range sticker.Options {
+			if option == "" || len(option) > 25 {
+				return errors.New("Poll options should be of length(1,25)")
+			}
+		}
+		sticker.Emoji = ""
+	case models.StickerQuestion:
+		if sticker.Prompt == "" {
+			return errors.New("Question sticker should have a prompt")
+		}
+		sticker.Options = nil
+		sticker.Emoji = ""
+	case models.StickerSlider:
+		if sticker.Emoji == "" || len(sticker.Emoji) > 16 {
+			return errors.New("Slider sticker should have an emoji")
+		}
+		sticker.Options = nil
+	default:
+		return errors.New("Sticker type should be poll, question or slider")
+	}
+	return nil
+}
+
+// returns the stickers placed on a story
+func getStoryStickers(storyId int64) ([]models.StorySticker, error) {
+	row, err := db.DB.Query("SELECT sticker_id,sticker_type,prompt,options,emoji FROM story_stickers WHERE story_id=$1 ORDER BY sticker_id", storyId)
+	if err != nil {
+		return nil, err
+	}
+	defer row.Close()
+
+	var stickers []models.StorySticker
+	for row.Next() {
+		var sticker models.StorySticker
+		err = row.Scan(&sticker.StickerId, &sticker.StickerType, &sticker.Prompt, pq.Array(&sticker.Options), &sticker.Emoji)
+		if err != nil {
+			return nil, err
+		}
+		stickers = append(stickers, sticker)
+	}
+	return stickers, nil
+}
+
+func AddStorySticker(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var sticker models.StoryStickerInfo
+	err := json.NewDecoder(r.Body).Decode(&sticker)
+	if err != nil {
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if sticker.UserID <= 0 || sticker.StoryId <= 0 {
+		http.Error(w, "Invalid ids or missing fields", http.StatusBadRequest)
+		return
+	}
+
+	if err = validateSticker(&sticker); err != nil {
+		http.Error(w, fmt.Sprint(err), http.StatusBadRequest)
+		return
+	}
+
+	var owner bool
+	err = db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM stories WHERE story_id=$1 AND user_id=$2)", sticker.StoryId, sticker.UserID).Scan(&owner)
+	if err != nil {
+		http.Error(w, "Error retrieving story", http.StatusInternalServerError)
+		return
+	}
+	if !owner {
+		http.Error(w, "Invalid story id", http.StatusBadRequest)
+		return
+	}
+
+	var count int
+	err = db.DB.QueryRow("SELECT COUNT(sticker_id) FROM story_stickers WHERE story_id=$1", sticker.StoryId).Scan(&count)
+	if err != nil {
+		http.Error(w, "Error retrieving stickers", http.StatusInternalServerError)
+		return
+	}
+	if count >= maxStickersPerStory {
+		http.Error(w, "Only 3 stickers allowed on a story", http.StatusBadRequest)
+		return
+	}
+
+	if sticker.Options == nil {
+		sticker.Options = []string{}
+	}
+
+	var stickerId models.ReturnedStickerId
+	err = db.DB.QueryRow("INSERT INTO story_stickers(story_id,sticker_type,prompt,options,emoji) VALUES($1,$2,$3,$4,$5) RETURNING sticker_id", sticker.StoryId, sticker.StickerType, sticker.Prompt, pq.Array(sticker.Options), sticker.Emoji).Scan(&stickerId.StickerId)
+	if err != nil {
+		http.Error(w, "Error adding sticker", http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(stickerId)
+}
+
+func RespondToSticker(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var response models.StickerResponse
+	err := json.NewDecoder(r.Body).Decode(&response)
+	if err != nil {
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if response.UserID <= 0 || response.StickerId <= 0 {
+		http.Error(w, "Invalid ids or missing fields", http.StatusBadRequest)
+		return
+	}
+
+	var storyId int64
+	var stickerType string
+	var options []string
+	err = db.DB.QueryRow("SELECT story_id,sticker_type,options FROM story_stickers WHERE sticker_id=$1", response.StickerId).Scan(&storyId, &stickerType, pq.Array(&options))
+	if err != nil {
+		http.Error(w, "Invalid sticker id", http.StatusBadRequest)
+		return
+	}
+
+	if !canRespondToStory(w, response.UserID, storyId) {
+		return
+	}
+
+	//keep only the field matching the sticker type
+	switch stickerType {
+	case models.StickerPoll:
+		if response.OptionIndex == nil || *response.OptionIndex < 0 || *response.OptionIndex >= len(options) {
+			http.Error(w, "Invalid poll option", http.StatusBadRequest)
+			return
+		}
+		response.Answer, response.SliderValue = nil, nil
+	case models.StickerQuestion:
+		if response.Answer == nil || *response.Answer == "" || len(*response.Answer) > 300 {
+			http.Error(w, "Answer should be of length(1,300)", http.StatusBadRequest)
+			return
+		}
+		response.OptionIndex, response.SliderValue = nil, nil
+	case models.StickerSlider:
+		if response.SliderValue == nil || *response.SliderValue < 0 || *response.SliderValue > 1 {
+			http.Error(w, "Slider value should be between 0 and 1", http.StatusBadRequest)
+			return
+		}
+		response.OptionIndex, response.Answer = nil, nil
+	}
+
+	result, err := db.DB.Exec("INSERT INTO sticker_responses(sticker_id,user_id,option_index,answer,slider_value) VALUES($1,$2,$3,$4,$5) ON CONFLICT (sticker_id,user_id) DO NOTHING", response.StickerId, response.UserID, response.OptionIndex, response.Answer, response.SliderValue)
+	if err != nil {
+		http.Error(w, "Error saving response", http.StatusInternalServerError)
+		return
+	}
+	if inserted, _ := result.RowsAffected(); inserted == 0 {
+		http.Error(w, "You have already responded to this sticker", http.StatusConflict)
+		return
+	}
+	fmt.Fprintln(w, "Response recorded")
+}
+
+func StickerResults(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request models.StickerResponse
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if request.UserID <= 0 || request.StickerId <= 0 {
+		http.Error(w, "Invalid ids or missing fields", http.StatusBadRequest)
+		return
+	}
+
+	//results are visible only to the author of the story
+	var results models.StickerResults
+	var options []string
+	err = db.DB.QueryRow(`SELECT k.sticker_id,k.sticker_type,k.prompt,k.options FROM story_stickers k
+		JOIN stories s ON s.story_id=k.story_id
+		WHERE k.sticker_id=$1 AND s.user_id=$2`, request.StickerId, request.UserID).Scan(&results.StickerId, &results.StickerType, &results.Prompt, pq.Array(&options))
+	if err != nil {
+		http.Error(w, "Invalid sticker id", http.StatusBadRequest)
+		return
+	}
+
+	err = db.DB.QueryRow("SELECT COUNT(user_id) FROM sticker_responses WHERE sticker_id=$1", request.StickerId).Scan(&results.TotalResponses)
+	if err != nil {
+		http.Error(w, "Error retrieving responses", http.StatusInternalServerError)
+		return
+	}
+
+	switch results.StickerType {
+	case models.StickerPoll:
+		results.OptionVotes = make([]int64, len(options))
+		row, err := db.DB.Query("SELECT option_index,COUNT(user_id) FROM sticker_responses WHERE sticker_id=$1 GROUP BY option_index", request.StickerId)
+		if err != nil {
+			http.Error(w, "Error retrieving votes", http.StatusInternalServerError)
+			return
+		}
+		defer row.Close()
+		for row.Next() {
+			var index int
+			var votes int64
+			err = row.Scan(&index, &votes)
+			if err != nil {
+				http.Error(w, "Error reading votes", http.StatusInternalServerError)
+				return
+			}
+			if index >= 0 && index < len(options) {
+				results.OptionVotes[index] = votes
+			}
+		}
+	case models.StickerSlider:
+		if results.TotalResponses > 0 {
+			var average float64
+			err = db.DB.QueryRow("SELECT AVG(slider_value) FROM sticker_responses WHERE sticker_id=$1", request.StickerId).Scan(&average)
+			if err != nil {
+				http.Error(w, "Error retrieving slider average", http.StatusInternalServerError)
+				return
+			}
+			results.SliderAverage = &average
+		}
+	case models.StickerQuestion:
+		row, err := db.DB.Query(`SELECT r.user_id,u.user_name,r.answer,r.responded_on FROM sticker_responses r
+			JOIN users u ON u.user_id=r.user_id
+			WHERE r.sticker_id=$1 ORDER BY r.responded_on DESC`, request.StickerId)
+		if err != nil {
+			http.Error(w, "Error retrieving answers", http.StatusInternalServerError)
+			return
+		}
+		defer row.Close()
+		for row.Next() {
+			var answer models.StickerAnswer
+			err = row.Scan(&answer.UserID, &answer.UserName, &answer.Answer, &answer.AnsweredOn)
+			if err != nil {
+				http.Error(w, "Error reading answers", http.StatusInternalServerError)
+				return
+			}
+			results.Answers = append(results.Answers, answer)
+		}
+	}
+
+	json.NewEncoder(w).Encode(results)
+}
@@ -5,19 +5,33 @@ import (
 	"backend/models"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"os"
 	"path"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// returns the audience of a new story, everyone when not given
+func storyAudience(audience string) (string, error) {
+	switch audience {
+	case "":
+		return models.StoryAudienceEveryone, nil
+	case models.StoryAudienceEveryone, models.StoryAudienceCloseFriends:
+		return audience, nil
+	default:
+		return "", errors.New("Story audience should be everyone or close_friends")
+	}
+}
+
 func UploadStory(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -35,6 +49,13 @@ func UploadStory(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Maximum 20 ids allowed", http.StatusBadRequest)
 		return
 	}
+
+	storyinfo.Audience, err = storyAudience(storyinfo.Audience)
+	if err != nil {
+		http.Error(w, fmt.Sprint(err), http.StatusBadRequest)
+		return
+	}
+
 	var idexists bool
 	err = db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE user_id=$1)", storyinfo.UserID).Scan(&idexists)
 	if err != nil {
@@ -46,13 +67,67 @@ func UploadStory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	//tags with positions take precedence over bare tagged ids
+	storyTags := storyinfo.Tags
+	if len(storyTags) == 0 {
+		for _, ids := range storyinfo.TaggedIds {
+			var tags []models.StoryTag
+			for _, id := range ids {
+				tags = append(tags, models.StoryTag{UserID: id, X: 0.5, Y: 0.5})
+			}
+			storyTags = append(storyTags, tags)
+		}
+	}
+
+	if len(storyTags) > 20 {
+		http.Error(w, "Maximum 20 ids allowed", http.StatusBadRequest)
+		return
+	}
+
+	var taggedIds []int64
+	for _, tags := range storyTags {
+		if err = validateStoryTags(tags); err != nil {
+			http.Error(w, fmt.Sprint(err), http.StatusBadRequest)
+			return
+		}
+		for _, tag := range tags {
+			taggedIds = append(taggedIds, tag.UserID)
+		}
+	}
+
+	//users across a block can't be tagged
+	isBlocked, err := blockedAny(storyinfo.UserID, taggedIds)
+	if err != nil {
+		http.Error(w, "Error checking tagged users", http.StatusInternalServerError)
+		return
+	}
+	if isBlocked {
+		http.Error(w, "You can't tag one of these users", http.StatusForbidden)
+		return
+	}
+
 	var storyIds []models.ReturnedStoryId
 
-	//check for existance of all tagged ids
-	for _, ids := range storyinfo.TaggedIds {
+	for _, tags := range storyTags {
+		var count int64
+		err = db.DB.QueryRow("SELECT COUNT(story_id) FROM stories WHERE user_id=$1", storyinfo.UserID).Scan(&count)
+		if err != nil {
+			http.Error(w, "Error retrieving count of stories of a user", http.StatusInternalServerError)
+			return
+		}
+
+		if count >= 100 {
+			//stories saved to a highlight are kept out of the rotation
+			_, err = db.DB.Exec(`DELETE FROM stories WHERE story_id=(SELECT MIN(s.story_id) FROM stories s WHERE s.user_id=$1
+				AND NOT EXISTS(SELECT 1 FROM highlight_stories h WHERE h.story_id=s.story_id))`, storyinfo.UserID)
+			if err != nil {
+				http.Error(w, "Coudn't delete initial post ", http.StatusInternalServerError)
+				return
+			}
+		}
 
 		var returnedStoryId models.ReturnedStoryId
-		err = db.DB.QueryRow("INSERT INTO stories(user_id,story_path) VALUES($1,$2) RETURNING story_id", storyinfo.UserID, "").Scan(&returnedStoryId.ReturnedStoryId)
+		err = db.DB.QueryRow("INSERT INTO stories(user_id,story_path,audience) VALUES($1,$2,$3) RETURNING story_id", storyinfo.UserID, "", storyinfo.Audience).Scan(&returnedStoryId.ReturnedStoryId)
 		if err != nil {
 			panic(err)
 		}
@@ -60,59 +135,73 @@ func UploadStory(w http.ResponseWriter, r *http.Request) {
 		returnedStoryId.PostAsStory = false
 		storyIds = append(storyIds, returnedStoryId)
 
-		for _, id := range ids {
-			var count int64
-			err = db.DB.QueryRow("SELECT COUNT(story_id) FROM stories WHERE user_id=$1", storyinfo.UserID).Scan(&count)
-			if err != nil {
-				http.Error(w, "Error retrieving count of stories of a user", http.StatusInternalServerError)
-				return
-			}
+		err = insertStoryTags(returnedStoryId.ReturnedStoryId, tags)
+		if err != nil {
+			http.Error(w, "Error inserting to story_tags table", http.StatusInternalServerError)
+			return
+		}
+	}
+	json.NewEncoder(w).Encode(storyIds)
 
-			if count < 100 {
-
-				err = db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE user_id=$1)", id).Scan(&idexists)
-				if err != nil {
-					http.Error(w, "Invalid user-id", http.StatusInternalServerError)
-					return
-				}
-				if !idexists {
-					http.Error(w, "No user exists with this id:", http.StatusInternalServerError)
-					fmt.Fprint(w, id)
-					return
-				}
-				_, err = db.DB.Query("INSERT INTO story_tags(story_id,tagged_id) VALUES($1,$2)", returnedStoryId.ReturnedStoryId, id)
-				if err != nil {
-					log.Panic(err)
-					// http.Error(w, "Error inserting to story_tags table", http.StatusInternalServerError)
-					// return
-				}
-			} else {
-				_, err = db.DB.Query("DELETE FROM stories WHERE story_id=(SELECT MIN(story_id) WHERE user_id=$1)", storyinfo.UserID)
-				if err != nil {
-					http.Error(w, "Coudn't delete initial post ", http.StatusInternalServerError)
-					return
-				}
-				err = db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE user_id=$1)", id).Scan(&idexists)
-				if err != nil {
-					http.Error(w, "Invalid user-id", http.StatusInternalServerError)
-					return
-				}
-				if !idexists {
-					http.Error(w, "No user exists with this id:", http.StatusInternalServerError)
-					fmt.Fprint(w, id)
-					return
-				}
-				_, err = db.DB.Query("INSERT INTO story_tags(story_id,tagged_id) VALUES($1,$2)", returnedStoryId, id)
-				if err != nil {
-					http.Error(w, "Error inserting to story_tags table", http.StatusInternalServerError)
-					return
-				}
-			}
+}
 
+// validates positions of the tags and checks the tagged users exist
+func validateStoryTags(tags []models.StoryTag) error {
+	if len(tags) > 20 {
+		return errors.New("Only 20 users can be tagged in a story")
+	}
+	for _, tag := range tags {
+		if tag.X < 0 || tag.X > 1 || tag.Y < 0 || tag.Y > 1 {
+			return errors.New("Tag position should be between 0 and 1")
+		}
+		if tag.Rotation < -360 || tag.Rotation > 360 {
+			return errors.New("Tag rotation should be between -360 and 360")
+		}
+
+		var idexists bool
+		err := db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE user_id=$1)", tag.UserID).Scan(&idexists)
+		if err != nil {
+			return err
+		}
+		if !idexists {
+			return fmt.Errorf("No user exists with this id:%d", tag.UserID)
 		}
 	}
-	json.NewEncoder(w).Encode(storyIds)
+	return nil
+}
 
+// stores tags of a story with their positions, tags of users who restricted the author wait for approval
+func insertStoryTags(storyId int64, tags []models.StoryTag) error {
+	for _, tag := range tags {
+		_, err := db.DB.Exec(`INSERT INTO story_tags(story_id,tagged_id,x,y,rotation,approved) VALUES($1,$2,$3,$4,$5,
+			NOT EXISTS(SELECT 1 FROM restricts r JOIN stories s ON s.user_id=r.restricted_id WHERE s.story_id=$1 AND r.user_id=$2))`, storyId, tag.UserID, tag.X, tag.Y, tag.Rotation)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifies users tagged in a published story
+func notifyStoryMentions(storyId int64) error {
+	row, err := db.DB.Query("SELECT t.tagged_id,s.user_id FROM story_tags t JOIN stories s ON s.story_id=t.story_id WHERE t.story_id=$1 AND t.approved=$2", storyId, true)
+	if err != nil {
+		return err
+	}
+	defer row.Close()
+
+	for row.Next() {
+		var taggedId, ownerId int64
+		err = row.Scan(&taggedId, &ownerId)
+		if err != nil {
+			return err
+		}
+		err = notifyStory(taggedId, ownerId, models.NotificationStoryMention, storyId)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func UploadStoryPath(w http.ResponseWriter, r *http.Request) {
@@ -210,10 +299,179 @@ func UploadStoryPath(w http.ResponseWriter, r *http.Request) {
 		// http.Error(w, "Error inserting story media", http.StatusInternalServerError)
 		return
 	}
+
+	//tagged users are told once the story is published
+	err = notifyStoryMentions(upload.StoryId)
+	if err != nil {
+		http.Error(w, "Error notifying tagged users", http.StatusInternalServerError)
+		return
+	}
 	json.NewEncoder(w).Encode(upload)
 
 }
 
+func SharePostToStory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var share models.PostAsStory
+	err := json.NewDecoder(r.Body).Decode(&share)
+	if err != nil {
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if share.UserID <= 0 || share.PostId <= 0 {
+		http.Error(w, "Invalid ids or missing fields", http.StatusBadRequest)
+		return
+	}
+
+	if len(share.TaggedIds) > 20 {
+		http.Error(w, "Maximum 20 ids allowed", http.StatusBadRequest)
+		return
+	}
+
+	share.Audience, err = storyAudience(share.Audience)
+	if err != nil {
+		http.Error(w, fmt.Sprint(err), http.StatusBadRequest)
+		return
+	}
+
+	var authorId int64
+	var postPath string
+	err = db.DB.QueryRow("SELECT user_id,post_path FROM posts WHERE post_id=$1 AND complete_post=$2", share.PostId, true).Scan(&authorId, &postPath)
+	if err != nil {
+		http.Error(w, "Invalid postId or does not exist", http.StatusBadRequest)
+		return
+	}
+
+	//posts across a block can't be shared
+	isBlocked, err := blockedBetween(share.UserID, authorId)
+	if err != nil {
+		http.Error(w, "Error retrieving post author", http.StatusInternalServerError)
+		return
+	}
+	if isBlocked {
+		http.Error(w, "Invalid postId or does not exist", http.StatusNotFound)
+		return
+	}
+
+	//posts of private accounts can be shared only by their author
+	var private bool
+	err = db.DB.QueryRow("SELECT private FROM users WHERE user_id=$1", authorId).Scan(&private)
+	if err != nil {
+		http.Error(w, "Error retrieving post author", http.StatusInternalServerError)
+		return
+	}
+	if private && authorId != share.UserID {
+		http.Error(w, "Posts from private accounts can't be shared", http.StatusForbidden)
+		return
+	}
+
+	var tags []models.StoryTag
+	for _, id := range share.TaggedIds {
+		tags = append(tags, models.StoryTag{UserID: id, X: 0.5, Y: 0.5})
+	}
+	if err = validateStoryTags(tags); err != nil {
+		http.Error(w, fmt.Sprint(err), http.StatusBadRequest)
+		return
+	}
+
+	//users across a block can't be tagged
+	isBlocked, err = blockedAny(share.UserID, share.TaggedIds)
+	if err != nil {
+		http.Error(w, "Error checking tagged users", http.StatusInternalServerError)
+		return
+	}
+	if isBlocked {
+		http.Error(w, "You can't tag one of these users", http.StatusForbidden)
+		return
+	}
+
+	//story shows the first media of the post
+	storyPath := strings.Split(postPath, ",")[0]
+
+	returnedStoryId := models.ReturnedStoryId{PostAsStory: true}
+	err = db.DB.QueryRow("INSERT INTO stories(user_id,story_path,success,post_id,audience) VALUES($1,$2,$3,$4,$5) RETURNING story_id", share.UserID, storyPath, true, share.PostId, share.Audience).Scan(&returnedStoryId.ReturnedStoryId)
+	if err != nil {
+		http.Error(w, "Error sharing post to story", http.StatusInternalServerError)
+		return
+	}
+
+	err = insertStoryTags(returnedStoryId.ReturnedStoryId, tags)
+	if err != nil {
+		http.Error(w, "Error inserting to story_tags table", http.StatusInternalServerError)
+		return
+	}
+
+	err = notifyStoryMentions(returnedStoryId.ReturnedStoryId)
+	if err != nil {
+		http.Error(w, "Error notifying tagged users", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(returnedStoryId)
+}
+
+func ReshareStory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var storyid models.StoryMedia
+	err := json.NewDecoder(r.Body).Decode(&storyid)
+	if err != nil {
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if storyid.UserID <= 0 || storyid.StoryId <= 0 {
+		http.Error(w, "Invalid ids or missing fields", http.StatusBadRequest)
+		return
+	}
+
+	//only users mentioned in the story can reshare it, keeping the audience of the original
+	var mentioned bool
+	err = db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM story_tags WHERE story_id=$1 AND tagged_id=$2 AND approved=$3)", storyid.StoryId, storyid.UserID, true).Scan(&mentioned)
+	if err != nil {
+		http.Error(w, "Error retrieving story tags", http.StatusInternalServerError)
+		return
+	}
+	visible, _, err := canViewStory(storyid.UserID, storyid.StoryId)
+	if err != nil {
+		http.Error(w, "Error retrieving story", http.StatusInternalServerError)
+		return
+	}
+	if !mentioned || !visible {
+		http.Error(w, "You can reshare only stories you are mentioned in", http.StatusForbidden)
+		return
+	}
+
+	var alreadyReshared bool
+	err = db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM stories WHERE user_id=$1 AND reshared_from=$2)", storyid.UserID, storyid.StoryId).Scan(&alreadyReshared)
+	if err != nil {
+		http.Error(w, "Error retrieving story", http.StatusInternalServerError)
+		return
+	}
+	if alreadyReshared {
+		http.Error(w, "Story already reshared", http.StatusConflict)
+		return
+	}
+
+	var returnedStoryId models.ReturnedStoryId
+	err = db.DB.QueryRow(`INSERT INTO stories(user_id,story_path,success,post_id,reshared_from,audience)
+		SELECT $1,story_path,$2,post_id,story_id,audience FROM stories WHERE story_id=$3 RETURNING story_id,post_id IS NOT NULL`, storyid.UserID, true, storyid.StoryId).Scan(&returnedStoryId.ReturnedStoryId, &returnedStoryId.PostAsStory)
+	if err != nil {
+		http.Error(w, "Error resharing story", http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(returnedStoryId)
+}
+
 func GetStory(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -232,45 +490,70 @@ func GetStory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	//validate storyId
-	var storyExists bool
-	err = db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM stories WHERE story_id=$1)", storyid.StoryId).Scan(&storyExists)
+	//validate storyId and audience of the story
+	visible, _, err := canViewStory(storyid.UserID, storyid.StoryId)
 	if err != nil {
-		http.Error(w, "Invalid storyId", http.StatusBadRequest)
+		http.Error(w, "Error retrieving story", http.StatusInternalServerError)
 		return
 	}
 
-	if !storyExists {
+	if !visible {
 		http.Error(w, "Invalid storyId", http.StatusBadRequest)
 		return
 	}
 
 	var getstory models.GetStory
-	err = db.DB.QueryRow("SELECT story_id,story_path,posted_on,success FROM stories WHERE story_id=$1", storyid.StoryId).Scan(&getstory.StoryId, &getstory.StoryURL, &getstory.PostedOn, &getstory.Success)
+	var sharedPostId, resharedFrom sql.NullInt64
+	err = db.DB.QueryRow("SELECT story_id,story_path,posted_on,success,audience,post_id,reshared_from FROM stories WHERE story_id=$1", storyid.StoryId).Scan(&getstory.StoryId, &getstory.StoryURL, &getstory.PostedOn, &getstory.Success, &getstory.Audience, &sharedPostId, &resharedFrom)
 	if err != nil {
 		panic(err)
 	}
+	getstory.Reshared = resharedFrom.Int64
 
-	row, err := db.DB.Query("SELECT tagged_id FROM story_tags WHERE story_id=$1", storyid.StoryId)
+	//attribution back to the original post
+	if sharedPostId.Valid {
+		sharedPost := models.SharedPost{PostId: sharedPostId.Int64}
+		err = db.DB.QueryRow("SELECT p.user_id,u.user_name FROM posts p JOIN users u ON u.user_id=p.user_id WHERE p.post_id=$1", sharedPostId.Int64).Scan(&sharedPost.UserID, &sharedPost.UserName)
+		if err != nil {
+			http.Error(w, "Error retrieving shared post", http.StatusInternalServerError)
+			return
+		}
+		sharedPost.PostURL = "http://localhost:3000/getpost/" + fmt.Sprint(sharedPost.PostId)
+		getstory.SharedPost = &sharedPost
+	}
+
+	row, err := db.DB.Query("SELECT tagged_id,x,y,rotation FROM story_tags WHERE story_id=$1 AND approved=$2", storyid.StoryId, true)
 	if err != nil {
 		http.Error(w, "Error getting tagged ids", http.StatusInternalServerError)
 		return
 	}
+	defer row.Close()
 
 	for row.Next() {
-		var tagged_ids int64
-		err = row.Scan(&tagged_ids)
+		var tag models.StoryTag
+		err = row.Scan(&tag.UserID, &tag.X, &tag.Y, &tag.Rotation)
 		if err != nil {
 			http.Error(w, "Scan error on tagged_id", http.StatusInternalServerError)
 			return
 		}
 
-		getstory.TaggedIds = append(getstory.TaggedIds, tagged_ids)
+		getstory.TaggedIds = append(getstory.TaggedIds, tag.UserID)
+		getstory.Tags = append(getstory.Tags, tag)
 
 	}
 	filetype := strings.Split(getstory.StoryURL, ".")
 	getstory.FileType = models.GetExtension("." + filetype[len(filetype)-1])
-	getstory.StoryURL = "http://localhost:3000/download/" + getstory.StoryURL
+	if strings.HasPrefix(getstory.StoryURL, "stories/") {
+		getstory.StoryURL = fmt.Sprintf("http://localhost:3000/download/%s?story_id=%d&user_id=%d", getstory.StoryURL, storyid.StoryId, storyid.UserID)
+	} else {
+		getstory.StoryURL = "http://localhost:3000/download/" + getstory.StoryURL
+	}
+
+	getstory.Stickers, err = getStoryStickers(getstory.StoryId)
+	if err != nil {
+		http.Error(w, "Error getting story stickers", http.StatusInternalServerError)
+		return
+	}
 
 	json.NewEncoder(w).Encode(getstory)
 
@@ -282,9 +565,31 @@ func DownloadStory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	url := fmt.Sprint(r.URL)
+	_, file := path.Split(r.URL.Path)
+
+	//story media is served only to viewers in the audience of the story passed as ?story_id=
+	viewerId, _ := strconv.ParseInt(r.URL.Query().Get("user_id"), 10, 64)
+	storyId, _ := strconv.ParseInt(r.URL.Query().Get("story_id"), 10, 64)
+
+	var matches bool
+	err := db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM stories WHERE story_id=$1 AND story_path=$2)", storyId, "stories/"+file).Scan(&matches)
+	if err != nil {
+		http.Error(w, "Error retrieving story", http.StatusInternalServerError)
+		return
+	}
 
-	_, file := path.Split(url)
+	visible := false
+	if matches {
+		visible, _, err = canViewStory(viewerId, storyId)
+		if err != nil {
+			http.Error(w, "Error retrieving story", http.StatusInternalServerError)
+			return
+		}
+	}
+	if !visible {
+		http.Error(w, "Story not found", http.StatusNotFound)
+		return
+	}
 
 	imagePath := "./stories/" + file
 
@@ -331,15 +636,30 @@ func DeleteStory(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var storypath string
-	err = db.DB.QueryRow("SELECT story_path FROM stories WHERE story_id=$1", storyid.StoryId).Scan(&storypath)
+	var sharedMedia bool
+	err = db.DB.QueryRow("SELECT story_path,post_id IS NOT NULL OR reshared_from IS NOT NULL FROM stories WHERE story_id=$1", storyid.StoryId).Scan(&storypath, &sharedMedia)
 	if err != nil {
 		http.Error(w, "Invalid id", http.StatusBadRequest)
 		return
 	}
 
-	filelocation := "./" + storypath
+	//media of a shared post or reshared story belongs to the original
+	if !sharedMedia {
+		filelocation := "./" + storypath
+		os.Remove(filelocation)
+	}
 
-	os.Remove(filelocation)
+	//remove reactions and replies sent to the story
+	_, err = db.DB.Exec("DELETE FROM story_reactions WHERE story_id=$1", storyid.StoryId)
+	if err != nil {
+		http.Error(w, "Error deleting story reactions", http.StatusInternalServerError)
+		return
+	}
+	_, err = db.DB.Exec("DELETE FROM story_replies WHERE story_id=$1", storyid.StoryId)
+	if err != nil {
+		http.Error(w, "Error deleting story replies", http.StatusInternalServerError)
+		return
+	}
 
 	_, err = db.DB.Query("DELETE FROM stories WHERE story_id=$1", storyid.StoryId)
 	if err != nil {
@@ -382,65 +702,129 @@ func AllActiveStories(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var following []int64
-	row, err := db.DB.Query("SELECT follower_id FROM follower WHERE user_id=$1 AND accepted=$2", userId.UserId, true)
-	if err != nil {
-		log.Panicln("no follwings", err)
+	if userId.UserId <= 0 {
+		http.Error(w, "Invalid user id or missing field", http.StatusBadRequest)
+		return
 	}
-	for row.Next() {
-		var id int64
-		err = row.Scan(&id)
-		if err == sql.ErrNoRows {
-			fmt.Fprintln(w, "null")
-			return
-		}
-		following = append(following, id)
+
+	//active stories of the viewer and accepted followees in one query, oldest first, muted accounts left out
+	row, err := db.DB.Query(`SELECT s.story_id,s.user_id,u.user_name,u.display_pic,s.posted_on,
+		s.user_id=$1 OR EXISTS(SELECT 1 FROM story_seen_status v WHERE v.story_id=s.story_id AND v.user_id=$1)
+		FROM stories s JOIN users u ON u.user_id=s.user_id
+		WHERE s.success=$2 AND s.posted_on > current_timestamp - interval '24 hours'
+		AND (s.user_id=$1 OR s.user_id IN (SELECT follower_id FROM follower WHERE user_id=$1 AND accepted=$2))
+		AND (s.user_id=$1 OR s.audience=$3 OR EXISTS(SELECT 1 FROM close_friends c WHERE c.user_id=s.user_id AND c.friend_id=$1))
+		AND NOT EXISTS(SELECT 1 FROM mutes m WHERE m.user_id=$1 AND m.muted_id=s.user_id AND m.mute_stories=$2)
+		ORDER BY s.posted_on ASC,s.story_id ASC`, userId.UserId, true, models.StoryAudienceEveryone)
+	if err != nil {
+		http.Error(w, "Error retrieving active stories", http.StatusInternalServerError)
+		return
 	}
+	defer row.Close()
 
 	var activeStory []models.ActiveStories
-	for _, id := range following {
-		var story models.ActiveStories
-		row, err := db.DB.Query("SELECT story_id FROM stories WHERE user_id=$1 AND success =$2", id, true)
+	latest := make(map[int64]time.Time)
+	rings := make(map[int64]int)
+	for row.Next() {
+		var story models.RingStory
+		var authorId int64
+		var userName, displayPic string
+		var postedOn time.Time
+		err = row.Scan(&story.StoryId, &authorId, &userName, &displayPic, &postedOn, &story.Seen)
 		if err != nil {
-			panic(err)
+			http.Error(w, "Error reading active stories", http.StatusInternalServerError)
+			return
 		}
-		err = db.DB.QueryRow("SELECT user_name,display_pic FROM users WHERE user_id=$1", id).Scan(&story.User_name, &story.Profile_picURL)
-		if err != nil {
-			log.Panicln(err)
+		story.PostedOn = postedOn.Format(time.RFC3339Nano)
+
+		i, ok := rings[authorId]
+		if !ok {
+			i = len(activeStory)
+			rings[authorId] = i
+			activeStory = append(activeStory, models.ActiveStories{
+				User_id:        authorId,
+				User_name:      userName,
+				Profile_picURL: "http://localhost:3000/getProfilePic/" + displayPic,
+				Seen_status:    true,
+			})
 		}
-		story.Profile_picURL = "http://localhost:3000/getProfilePic/profilePhoto/" + story.Profile_picURL
-
-		story.User_id = id
-		for row.Next() {
-			var story_id int64
-			err = row.Scan(&story_id)
-			if err != nil {
-				log.Panicln("no story id")
-			}
-			story.Story_id = append(story.Story_id, story_id)
-			err = db.DB.QueryRow("SELECT seen_status FROM story_seen_status WHERE user_id=$1 AND story_id=$2", userId.UserId, story_id).Scan(&story.Seen_status)
-			if err != nil {
-				//log.Panicln("no seen status", err)
-				continue
-			}
-
+		ring := &activeStory[i]
+		ring.Story_id = append(ring.Story_id, story.StoryId)
+		ring.Stories = append(ring.Stories, story)
+		//ring is seen only when every story in it is seen
+		ring.Seen_status = ring.Seen_status && story.Seen
+		ring.LatestPostedOn = story.PostedOn
+		latest[authorId] = postedOn
+	}
+
+	//own ring first, then unseen rings, then most recent
+	sort.SliceStable(activeStory, func(i, j int) bool {
+		a, b := activeStory[i], activeStory[j]
+		if (a.User_id == userId.UserId) != (b.User_id == userId.UserId) {
+			return a.User_id == userId.UserId
 		}
-		activeStory = append(activeStory, story)
-	}
+		if a.Seen_status != b.Seen_status {
+			return !a.Seen_status
+		}
+		return latest[a.User_id].After(latest[b.User_id])
+	})
+
 	w.Header().Set("Content-Type", "application/json")
 
 	json.NewEncoder(w).Encode(activeStory)
 }
 
 func UpdateStorySeenStatus(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
+	//GET with a post_id body is the older contract returning the upload status of the story, kept for existing clients
+	if r.Method == http.MethodGet {
+		legacyStorySeenStatus(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
 		http.Error(w, "Method Not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	var seen models.UpdateStorySeenStatus
+	err := json.NewDecoder(r.Body).Decode(&seen)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if seen.UserID <= 0 || seen.StoryId <= 0 {
+		http.Error(w, "Invalid ids or missing fields", http.StatusBadRequest)
+		return
+	}
+
+	visible, ownerId, err := canViewStory(seen.UserID, seen.StoryId)
+	if err != nil {
+		http.Error(w, "Error retrieving story", http.StatusInternalServerError)
+		return
+	}
+	if !visible {
+		http.Error(w, "Invalid story id", http.StatusBadRequest)
+		return
+	}
+
+	//views of the owner are not counted
+	if ownerId != seen.UserID {
+		_, err = db.DB.Exec("INSERT INTO story_seen_status(user_id,story_id,seen_status) VALUES($1,$2,$3) ON CONFLICT (story_id,user_id) DO NOTHING", seen.UserID, seen.StoryId, true)
+		if err != nil {
+			http.Error(w, "Error updating seen status", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	json.NewEncoder(w).Encode(models.StorySeen{StoryId: seen.StoryId, SeenStatus: true})
+}
+
+// returns the upload status of a story sent as post_id
+func legacyStorySeenStatus(w http.ResponseWriter, r *http.Request) {
 	var story_id models.PostId
 	err := json.NewDecoder(r.Body).Decode(&story_id)
 	if err != nil {
-		panic(err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
 	}
 	var postUploadStatus models.SavedStatus
 	err = db.DB.QueryRow("SELECT success FROM stories WHERE story_id=$1", story_id.PostId).Scan(&postUploadStatus.SavedStatus)
@@ -451,3 +835,68 @@ func UpdateStorySeenStatus(w http.ResponseWriter, r *http.Request) {
 
 	json.NewEncoder(w).Encode(postUploadStatus)
 }
+
+func StoryViewers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var request models.StoryViewersRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if request.UserID <= 0 || request.StoryId <= 0 {
+		http.Error(w, "Invalid ids or missing fields", http.StatusBadRequest)
+		return
+	}
+
+	//only the owner of the story can see who viewed it
+	var owner bool
+	err = db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM stories WHERE story_id=$1 AND user_id=$2)", request.StoryId, request.UserID).Scan(&owner)
+	if err != nil {
+		http.Error(w, "Error retrieving story", http.StatusInternalServerError)
+		return
+	}
+	if !owner {
+		http.Error(w, "Invalid story id", http.StatusBadRequest)
+		return
+	}
+
+	var viewers models.StoryViewers
+	viewers.StoryId = request.StoryId
+
+	//viewers who restricted the story owner are not shown
+	err = db.DB.QueryRow(`SELECT COUNT(s.user_id) FROM story_seen_status s WHERE s.story_id=$1
+		AND NOT EXISTS(SELECT 1 FROM restricts r WHERE r.user_id=s.user_id AND r.restricted_id=$2)`, request.StoryId, request.UserID).Scan(&viewers.ViewCount)
+	if err != nil {
+		http.Error(w, "Error retrieving view count", http.StatusInternalServerError)
+		return
+	}
+
+	limit, offset := pageBounds(request.Page, request.Limit)
+	row, err := db.DB.Query(`SELECT s.user_id,u.user_name,u.display_pic,s.seen_on FROM story_seen_status s
+		JOIN users u ON u.user_id=s.user_id
+		WHERE s.story_id=$1 AND NOT EXISTS(SELECT 1 FROM restricts r WHERE r.user_id=s.user_id AND r.restricted_id=$4)
+		ORDER BY s.seen_on DESC LIMIT $2 OFFSET $3`, request.StoryId, limit, offset, request.UserID)
+	if err != nil {
+		http.Error(w, "Error retrieving viewers", http.StatusInternalServerError)
+		return
+	}
+	defer row.Close()
+
+	for row.Next() {
+		var viewer models.StoryViewer
+		err = row.Scan(&viewer.UserID, &viewer.UserName, &viewer.ProfilePic, &viewer.SeenOn)
+		if err != nil {
+			http.Error(w, "Error reading viewers", http.StatusInternalServerError)
+			return
+		}
+		viewer.ProfilePic = "http://localhost:3000/getProfilePic/" + viewer.ProfilePic
+		viewers.Viewers = append(viewers.Viewers, viewer)
+	}
+
+	json.NewEncoder(w).Encode(viewers)
+}
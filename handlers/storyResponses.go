@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"backend/db"
+	"backend/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// checks that the viewer can respond to the story, viewers can't respond to their own stories
+func canRespondToStory(w http.ResponseWriter, userId, storyId int64) bool {
+	visible, ownerId, err := canViewStory(userId, storyId)
+	if err != nil {
+		http.Error(w, "Error retrieving story", http.StatusInternalServerError)
+		return false
+	}
+	if !visible {
+		http.Error(w, "Invalid story id", http.StatusBadRequest)
+		return false
+	}
+	if ownerId == userId {
+		http.Error(w, "Can't respond to your own story", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func ReactToStory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var reaction models.StoryReaction
+	err := json.NewDecoder(r.Body).Decode(&reaction)
+	if err != nil {
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if reaction.UserID <= 0 || reaction.StoryId <= 0 {
+		http.Error(w, "Invalid ids or missing fields", http.StatusBadRequest)
+		return
+	}
+
+	allowed := false
+	for _, emoji := range models.StoryReactionEmojis {
+		if emoji == reaction.Emoji {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		http.Error(w, "Unsupported reaction", http.StatusBadRequest)
+		return
+	}
+
+	if !canRespondToStory(w, reaction.UserID, reaction.StoryId) {
+		return
+	}
+
+	_, err = db.DB.Exec(`INSERT INTO story_reactions(story_id,user_id,emoji) VALUES($1,$2,$3)
+		ON CONFLICT (story_id,user_id) DO UPDATE SET emoji=EXCLUDED.emoji,reacted_on=current_timestamp`, reaction.StoryId, reaction.UserID, reaction.Emoji)
+	if err != nil {
+		http.Error(w, "Error reacting to story", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintln(w, "Reaction sent")
+}
+
+func ReplyToStory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var reply models.StoryReply
+	err := json.NewDecoder(r.Body).Decode(&reply)
+	if err != nil {
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if reply.UserID <= 0 || reply.StoryId <= 0 {
+		http.Error(w, "Invalid ids or missing fields", http.StatusBadRequest)
+		return
+	}
+	if reply.ReplyBody == "" {
+		http.Error(w, "Reply cannot be empty or missing field", http.StatusBadRequest)
+		return
+	}
+	if len(reply.ReplyBody) > 1000 {
+		http.Error(w, "Reply should not exceed 1000 characters", http.StatusBadRequest)
+		return
+	}
+
+	if !canRespondToStory(w, reply.UserID, reply.StoryId) {
+		return
+	}
+
+	var replyId models.ReturnedReplyId
+	err = db.DB.QueryRow("INSERT INTO story_replies(story_id,user_id,reply_body) VALUES($1,$2,$3) RETURNING reply_id", reply.StoryId, reply.UserID, reply.ReplyBody).Scan(&replyId.ReplyId)
+	if err != nil {
+		http.Error(w, "Error replying to story", http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(replyId)
+}
+
+func StoryResponses(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var storyid models.StoryMedia
+	err := json.NewDecoder(r.Body).Decode(&storyid)
+	if err != nil {
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if storyid.UserID <= 0 || storyid.StoryId <= 0 {
+		http.Error(w, "Invalid ids or missing fields", http.StatusBadRequest)
+		return
+	}
+
+	//replies are delivered only to the author of the story
+	var owner bool
+	err = db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM stories WHERE story_id=$1 AND user_id=$2)", storyid.StoryId, storyid.UserID).Scan(&owner)
+	if err != nil {
+		http.Error(w, "Error retrieving story", http.StatusInternalServerError)
+		return
+	}
+	if !owner {
+		http.Error(w, "Invalid story id", http.StatusBadRequest)
+		return
+	}
+
+	responses := models.StoryResponses{StoryId: storyid.StoryId}
+
+	row, err := db.DB.Query(`SELECT r.user_id,u.user_name,u.display_pic,r.emoji,r.reacted_on FROM story_reactions r
+		JOIN users u ON u.user_id=r.user_id
+		WHERE r.story_id=$1 ORDER BY r.reacted_on DESC`, storyid.StoryId)
+	if err != nil {
+		http.Error(w, "Error retrieving reactions", http.StatusInternalServerError)
+		return
+	}
+	defer row.Close()
+
+	for row.Next() {
+		var reaction models.StoryReactionEntry
+		err = row.Scan(&reaction.UserID, &reaction.UserName, &reaction.ProfilePic, &reaction.Emoji, &reaction.ReactedOn)
+		if err != nil {
+			http.Error(w, "Error reading reactions", http.StatusInternalServerError)
+			return
+		}
+		reaction.ProfilePic = "http://localhost:3000/getProfilePic/" + reaction.ProfilePic
+		responses.Reactions = append(responses.Reactions, reaction)
+	}
+
+	replies, err := db.DB.Query(`SELECT r.reply_id,r.user_id,u.user_name,u.display_pic,r.reply_body,r.replied_on FROM story_replies r
+		JOIN users u ON u.user_id=r.user_id
+		WHERE r.story_id=$1 ORDER BY r.replied_on DESC`, storyid.StoryId)
+	if err != nil {
+		http.Error(w, "Error retrieving replies", http.StatusInternalServerError)
+		return
+	}
+	defer replies.Close()
+
+	for replies.Next() {
+		var reply models.StoryReplyEntry
+		err = replies.Scan(&reply.ReplyId, &reply.UserID, &reply.UserName, &reply.ProfilePic, &reply.ReplyBody, &reply.RepliedOn)
+		if err != nil {
+			http.Error(w, "Error reading replies", http.StatusInternalServerError)
+			return
+		}
+		reply.ProfilePic = "http://localhost:3000/getProfilePic/" + reply.ProfilePic
+		responses.Replies = append(responses.Replies, reply)
+	}
+
+	json.NewEncoder(w).Encode(responses)
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"backend/db"
+	"backend/models"
+	"backend/src"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+func FollowSuggestions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request models.SuggestionsRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if request.UserID <= 0 {
+		http.Error(w, "Invalid user id or missing field", http.StatusBadRequest)
+		return
+	}
+
+	//users not reached by the cron job yet get their suggestions computed now
+	var computed bool
+	err = db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM suggestions_computed WHERE user_id=$1)", request.UserID).Scan(&computed)
+	if err != nil {
+		http.Error(w, "Error retrieving suggestions", http.StatusInternalServerError)
+		return
+	}
+	if !computed {
+		if err = src.ComputeSuggestions(request.UserID); err != nil {
+			http.Error(w, "Error computing suggestions", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	//follows, blocks and dismissals since the last computation are applied here
+	limit, offset := pageBounds(request.Page, request.Limit)
+	row, err := db.DB.Query(`SELECT u.user_id,u.user_name,u.name,u.display_pic,s.mutual_count,s.follows_you,s.tagged_together
+		FROM follow_suggestions s JOIN users u ON u.user_id=s.suggested_id
+		WHERE s.user_id=$1
+		AND NOT EXISTS(SELECT 1 FROM follower f WHERE f.user_id=$1 AND f.follower_id=s.suggested_id)
+		AND NOT EXISTS(SELECT 1 FROM blocks b WHERE (b.user_id=$1 AND b.blocked_id=s.suggested_id) OR (b.user_id=s.suggested_id AND b.blocked_id=$1))
+		ORDER BY s.score DESC,s.suggested_id LIMIT $2 OFFSET $3`, request.UserID, limit, offset)
+	if err != nil {
+		http.Error(w, "Error retrieving suggestions", http.StatusInternalServerError)
+		return
+	}
+	defer row.Close()
+
+	suggestions := []models.FollowSuggestion{}
+	for row.Next() {
+		var suggestion models.FollowSuggestion
+		err = row.Scan(&suggestion.UserID, &suggestion.UserName, &suggestion.Name, &suggestion.ProfilePic, &suggestion.MutualCount, &suggestion.FollowsYou, &suggestion.TaggedTogether)
+		if err != nil {
+			http.Error(w, "Error reading suggestions", http.StatusInternalServerError)
+			return
+		}
+		suggestion.ProfilePic = "http://localhost:3000/getProfilePic/" + suggestion.ProfilePic
+		suggestions = append(suggestions, suggestion)
+	}
+	json.NewEncoder(w).Encode(suggestions)
+}
+
+func DismissSuggestion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var dismiss models.DismissSuggestion
+	err := json.NewDecoder(r.Body).Decode(&dismiss)
+	if err != nil {
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if dismiss.UserID <= 0 || dismiss.SuggestedId <= 0 || dismiss.UserID == dismiss.SuggestedId {
+		http.Error(w, "Invalid ids or missing fields", http.StatusBadRequest)
+		return
+	}
+
+	_, err = db.DB.Exec("INSERT INTO dismissed_suggestions(user_id,suggested_id) VALUES($1,$2) ON CONFLICT DO NOTHING", dismiss.UserID, dismiss.SuggestedId)
+	if err != nil {
+		http.Error(w, "Error dismissing suggestion", http.StatusInternalServerError)
+		return
+	}
+
+	_, err = db.DB.Exec("DELETE FROM follow_suggestions WHERE user_id=$1 AND suggested_id=$2", dismiss.UserID, dismiss.SuggestedId)
+	if err != nil {
+		http.Error(w, "Error dismissing suggestion", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintln(w, "Suggestion dismissed")
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"backend/models"
+	"database/sql/driver"
+	"net/http"
+	"testing"
+)
+
+func TestFollowSuggestionsDoesNotRecomputeEmptyResult(t *testing.T) {
+	expectQueries(t,
+		fakeQuery{sql: "FROM suggestions_computed WHERE user_id=$1", columns: []string{"exists"}, rows: [][]driver.Value{{true}}},
+		fakeQuery{sql: "FROM follow_suggestions s JOIN users u", columns: []string{"user_id", "user_name", "name", "display_pic", "mutual_count", "follows_you", "tagged_together"}},
+	)
+
+	w := serve(FollowSuggestions, http.MethodGet, models.SuggestionsRequest{UserID: 2})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body)
+	}
+	if body := w.Body.String(); body != "[]\n" {
+		t.Errorf("expected no suggestions, got %s", body)
+	}
+}
+
+func TestFollowSuggestionsComputesNewUser(t *testing.T) {
+	expectQueries(t,
+		fakeQuery{sql: "FROM suggestions_computed WHERE user_id=$1", columns: []string{"exists"}, rows: [][]driver.Value{{false}}},
+		fakeQuery{sql: "DELETE FROM follow_suggestions WHERE user_id=$1"},
+		fakeQuery{sql: "INSERT INTO follow_suggestions"},
+		fakeQuery{sql: "INSERT INTO suggestions_computed(user_id)", args: []driver.Value{int64(2)}},
+		fakeQuery{sql: "FROM follow_suggestions s JOIN users u", columns: []string{"user_id", "user_name", "name", "display_pic", "mutual_count", "follows_you", "tagged_together"}},
+	)
+
+	w := serve(FollowSuggestions, http.MethodGet, models.SuggestionsRequest{UserID: 2})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body)
+	}
+}
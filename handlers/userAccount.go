@@ -4,10 +4,12 @@ import (
 	"backend/db"
 	"backend/models"
 	"backend/src"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"log"
 	"net/http"
 	"os"
 	"path"
@@ -281,6 +283,16 @@ func FollowOthers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	isBlocked, err := blockedBetween(x.MyId, x.Following)
+	if err != nil {
+		http.Error(w, "Error retrieving account", http.StatusInternalServerError)
+		return
+	}
+	if isBlocked {
+		http.Error(w, "You can't follow this account", http.StatusForbidden)
+		return
+	}
+
 	var private bool
 	err = db.DB.QueryRow("SELECT private FROM users WHERE user_id=$1", x.Following).Scan(&private)
 	if err != nil {
@@ -288,9 +300,10 @@ func FollowOthers(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if private == true {
-		_, err = db.DB.Query("INSERT INTO follower(user_id,follower_id,accepted) VALUES($1,$2,$3)", x.MyId, x.Following, false)
+		_, err = db.DB.Exec("INSERT INTO follower(user_id,follower_id,accepted) VALUES($1,$2,$3)", x.MyId, x.Following, false)
 		if err != nil {
-			_, err = db.DB.Query("DELETE FROM follower WHERE follower_id=$1", x.Following)
+			//already requested or following, remove only this pair
+			_, err = db.DB.Exec("DELETE FROM follower WHERE user_id=$1 AND follower_id=$2", x.MyId, x.Following)
 			if err != nil {
 				panic(err)
 			}
@@ -305,9 +318,10 @@ func FollowOthers(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if private == false {
-		_, err = db.DB.Query("INSERT INTO follower(user_id,follower_id) VALUES($1,$2)", x.MyId, x.Following)
+		_, err = db.DB.Exec("INSERT INTO follower(user_id,follower_id) VALUES($1,$2)", x.MyId, x.Following)
 		if err != nil {
-			_, err = db.DB.Query("DELETE FROM follower WHERE follower_id=$1", x.Following)
+			//already following, remove only this pair
+			_, err = db.DB.Exec("DELETE FROM follower WHERE user_id=$1 AND follower_id=$2", x.MyId, x.Following)
 			if err != nil {
 				panic(err)
 			}
@@ -328,46 +342,35 @@ func GetFollowers(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Method invalid", http.StatusMethodNotAllowed)
 		return
 	}
-	var userId models.UserID
+	var userId models.ProfileView
 	err := json.NewDecoder(r.Body).Decode(&userId)
 	if err != nil {
 		http.Error(w, "Invalid request body", http.StatusMethodNotAllowed)
 		return
 	}
 
-	var follower models.Follows
-	var followers []models.Follows
+	//following back is whether the viewer follows each follower, the owner when no viewer is given
+	if userId.ViewerId <= 0 {
+		userId.ViewerId = userId.UserId
+	}
 
-	row, err := db.DB.Query("SELECT user_id FROM follower WHERE follower_id=$1 AND accepted=$2", userId.UserId, true)
+	row, err := db.DB.Query(`SELECT u.user_id,u.name,u.user_name,u.display_pic,
+		EXISTS(SELECT 1 FROM follower b WHERE b.user_id=$2 AND b.follower_id=u.user_id AND b.accepted=$3)
+		FROM follower f JOIN users u ON u.user_id=f.user_id
+		WHERE f.follower_id=$1 AND f.accepted=$3`, userId.UserId, userId.ViewerId, true)
 	if err != nil {
 		panic(err)
 	}
+	defer row.Close()
 
+	var followers []models.Follows
 	for row.Next() {
-		err = row.Scan(&follower.UserID)
-		if err != nil {
-			panic(err)
-		}
-
-		err = db.DB.QueryRow("SELECT name,user_name,display_pic FROM users WHERE user_id=$1", follower.UserID).Scan(&follower.Name, &follower.UserName, &follower.ProfilePic)
+		var follower models.Follows
+		err = row.Scan(&follower.UserID, &follower.Name, &follower.UserName, &follower.ProfilePic, &follower.FollowingBackStatus)
 		if err != nil {
 			panic(err)
 		}
 		follower.ProfilePic = "http://localhost:3000/getProfilePic/" + follower.ProfilePic
-
-		//to check following back status
-		var id int64
-		err = db.DB.QueryRow("SELECT user_id FROM follower WHERE follower_id=$1 AND accepted=$2", follower.UserID, true).Scan(&id)
-		if err != nil {
-			follower.FollowingBackStatus = false
-		}
-
-		if id != userId.UserId {
-			follower.FollowingBackStatus = false
-		} else {
-			follower.FollowingBackStatus = true
-		}
-
 		followers = append(followers, follower)
 	}
 
@@ -422,9 +425,9 @@ func RespondingFollowRequests(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	//validate ids in db follower
+	//validate the pending request of this requestor in db follower
 	var user_id, follwer_id int64
-	err = db.DB.QueryRow("SELECT user_id,follower_id FROM follower WHERE follower_id=$1 AND accepted=$2", accepted.AcceptorUserID, false).Scan(&user_id, &follwer_id)
+	err = db.DB.QueryRow("SELECT user_id,follower_id FROM follower WHERE user_id=$1 AND follower_id=$2 AND accepted=$3", accepted.RequestorId, accepted.AcceptorUserID, false).Scan(&user_id, &follwer_id)
 	if err != nil {
 		http.Error(w, "Request doesn't exist", http.StatusInternalServerError)
 		return
@@ -446,6 +449,143 @@ func RespondingFollowRequests(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintln(w, "Deleted follow request")
 	}
 }
+
+// pending requests up to this count are accepted before responding, more are accepted in the background
+const inlineAcceptLimit = 200
+
+func SetAccountPrivacy(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var setting models.PrivacySetting
+	err := json.NewDecoder(r.Body).Decode(&setting)
+	if err != nil {
+		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		return
+	}
+
+	if setting.UserID <= 0 || setting.Private == nil {
+		http.Error(w, "Invalid user id or missing fields", http.StatusBadRequest)
+		return
+	}
+
+	//existing followers stay when going private
+	var wasPrivate bool
+	err = db.DB.QueryRow("UPDATE users u SET private=$1 FROM users o WHERE u.user_id=o.user_id AND u.user_id=$2 RETURNING o.private", *setting.Private, setting.UserID).Scan(&wasPrivate)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error updating account privacy", http.StatusInternalServerError)
+		return
+	}
+
+	status := models.PrivacyStatus{Private: *setting.Private}
+	if !wasPrivate || *setting.Private {
+		json.NewEncoder(w).Encode(status)
+		return
+	}
+
+	//going public accepts everyone who asked to follow
+	var pending int64
+	err = db.DB.QueryRow("SELECT COUNT(user_id) FROM follower WHERE follower_id=$1 AND accepted=$2", setting.UserID, false).Scan(&pending)
+	if err != nil {
+		http.Error(w, "Error retrieving follow requests", http.StatusInternalServerError)
+		return
+	}
+
+	if pending > inlineAcceptLimit {
+		status.AcceptingInBackground = true
+		go func(userId int64) {
+			//requests left pending on failure are picked up by the cron sweep
+			if _, err := src.AcceptPendingRequests(userId); err != nil {
+				log.Println("Error accepting pending follow requests", err)
+			}
+		}(setting.UserID)
+		json.NewEncoder(w).Encode(status)
+		return
+	}
+
+	status.AcceptedRequests, err = src.AcceptPendingRequests(setting.UserID)
+	if err != nil {
+		http.Error(w, "Error accepting follow requests", http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(status)
+}
+func SentFollowRequests(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var userId models.UserID
+	err := json.NewDecoder(r.Body).Decode(&userId)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if userId.UserId <= 0 {
+		http.Error(w, "Missing or invalid userId", http.StatusBadRequest)
+		return
+	}
+
+	row, err := db.DB.Query(`SELECT u.user_id,u.user_name,u.display_pic,f.created_at FROM follower f
+		JOIN users u ON u.user_id=f.follower_id
+		WHERE f.user_id=$1 AND f.accepted=$2 ORDER BY f.created_at DESC`, userId.UserId, false)
+	if err != nil {
+		http.Error(w, "Error retrieving follow requests", http.StatusInternalServerError)
+		return
+	}
+	defer row.Close()
+
+	var requests []models.SentFollowRequest
+	for row.Next() {
+		var request models.SentFollowRequest
+		err = row.Scan(&request.UserID, &request.UserName, &request.ProfilePic, &request.RequestedOn)
+		if err != nil {
+			http.Error(w, "Error reading follow requests", http.StatusInternalServerError)
+			return
+		}
+		request.ProfilePic = "http://localhost:3000/getProfilePic/" + request.ProfilePic
+		requests = append(requests, request)
+	}
+	json.NewEncoder(w).Encode(requests)
+}
+func CancelFollowRequest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var x models.Follow
+	err := json.NewDecoder(r.Body).Decode(&x)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if x.MyId <= 0 || x.Following <= 0 {
+		http.Error(w, "Invalid IDs or missing fields", http.StatusBadRequest)
+		return
+	}
+
+	//only a pending request of this pair is withdrawn, accepted follows are left alone
+	result, err := db.DB.Exec("DELETE FROM follower WHERE user_id=$1 AND follower_id=$2 AND accepted=$3", x.MyId, x.Following, false)
+	if err != nil {
+		http.Error(w, "Couldn't cancel follow request", http.StatusInternalServerError)
+		return
+	}
+	if deleted, _ := result.RowsAffected(); deleted == 0 {
+		http.Error(w, "Request doesn't exist", http.StatusBadRequest)
+		return
+	}
+	fmt.Fprintln(w, "Cancelled follow request")
+}
 func RemoveFollowers(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -477,30 +617,35 @@ func GetFollowing(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Method invalid", http.StatusMethodNotAllowed)
 		return
 	}
-	var userId models.UserID
+	var userId models.ProfileView
 	err := json.NewDecoder(r.Body).Decode(&userId)
 	if err != nil {
 		http.Error(w, "Invalid request body", http.StatusMethodNotAllowed)
 		return
 	}
 
-	row, err := db.DB.Query("SELECT follower_id FROM follower WHERE user_id=$1 AND accepted=$2", userId.UserId, true)
+	//following back is whether the viewer follows each account, the owner when no viewer is given
+	if userId.ViewerId <= 0 {
+		userId.ViewerId = userId.UserId
+	}
+
+	row, err := db.DB.Query(`SELECT u.user_id,u.name,u.user_name,u.display_pic,
+		EXISTS(SELECT 1 FROM follower b WHERE b.user_id=$2 AND b.follower_id=u.user_id AND b.accepted=$3)
+		FROM follower f JOIN users u ON u.user_id=f.follower_id
+		WHERE f.user_id=$1 AND f.accepted=$3`, userId.UserId, userId.ViewerId, true)
 	if err != nil {
 		panic(err)
 	}
+	defer row.Close()
+
 	var following []models.Follows
 	for row.Next() {
 		var follow models.Follows
-		err = row.Scan(&follow.UserID)
-		if err != nil {
-			panic(err)
-		}
-		err = db.DB.QueryRow("SELECT name,user_name,display_pic FROM users WHERE user_id=$1", follow.UserID).Scan(&follow.Name, &follow.UserName, &follow.ProfilePic)
+		err = row.Scan(&follow.UserID, &follow.Name, &follow.UserName, &follow.ProfilePic, &follow.FollowingBackStatus)
 		if err != nil {
 			panic(err)
 		}
 		follow.ProfilePic = "http://localhost:3000/getProfilePic/" + follow.ProfilePic
-		follow.FollowingBackStatus = true
 		following = append(following, follow)
 	}
 	json.NewEncoder(w).Encode(following)
@@ -558,13 +703,23 @@ func UpdateProfile(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	var userId models.UserID
+	var userId models.ProfileView
 	err := json.NewDecoder(r.Body).Decode(&userId)
 	if err != nil {
 		http.Error(w, "Invalid request body", http.StatusMethodNotAllowed)
 		return
 	}
 
+	isBlocked, err := blockedBetween(userId.ViewerId, userId.UserId)
+	if err != nil {
+		http.Error(w, "Error retrieving account", http.StatusInternalServerError)
+		return
+	}
+	if isBlocked {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
 	var profile models.Profile
 	var partialURL string
 
@@ -600,6 +755,30 @@ func UpdateProfile(w http.ResponseWriter, r *http.Request) {
 		panic(err)
 	}
 
+	//highlights of private accounts are visible only to accepted followers
+	visible, err := canViewProfile(userId.ViewerId, userId.UserId)
+	if err != nil {
+		http.Error(w, "Error checking profile visibility", http.StatusInternalServerError)
+		return
+	}
+	if visible {
+		profile.Highlights, err = getHighlights(userId.UserId, userId.ViewerId)
+		if err != nil {
+			http.Error(w, "Error retrieving highlights", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	//other viewers see how they are related to the account
+	if userId.ViewerId > 0 && userId.ViewerId != userId.UserId {
+		relationship, err := getRelationship(userId.ViewerId, userId.UserId)
+		if err != nil {
+			http.Error(w, "Error retrieving relationship", http.StatusInternalServerError)
+			return
+		}
+		profile.Relationship = &relationship
+	}
+
 	json.NewEncoder(w).Encode(profile)
 }
 func SavePosts(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"backend/db"
+	"backend/models"
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+// checks whether either user has blocked the other
+func blockedBetween(userId, otherId int64) (bool, error) {
+	var isBlocked bool
+	err := db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM blocks WHERE (user_id=$1 AND blocked_id=$2) OR (user_id=$2 AND blocked_id=$1))", userId, otherId).Scan(&isBlocked)
+	return isBlocked, err
+}
+
+// checks whether userId has blocked otherId
+func hasBlocked(userId, otherId int64) (bool, error) {
+	var isBlocked bool
+	err := db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM blocks WHERE user_id=$1 AND blocked_id=$2)", userId, otherId).Scan(&isBlocked)
+	return isBlocked, err
+}
+
+// checks whether userId has blocked or is blocked by any of ids
+func blockedAny(userId int64, ids []int64) (bool, error) {
+	if len(ids) == 0 {
+		return false, nil
+	}
+	var isBlocked bool
+	err := db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM blocks WHERE (user_id=$1 AND blocked_id=ANY($2)) OR (user_id=ANY($2) AND blocked_id=$1))", userId, pq.Array(ids)).Scan(&isBlocked)
+	return isBlocked, err
+}
+
+// checks whether viewerId is allowed to see the content of ownerId's account
+func canViewProfile(viewerId, ownerId int64) (bool, error) {
+	if viewerId == ownerId {
+		return true, nil
+	}
+
+	isBlocked, err := blockedBetween(viewerId, ownerId)
+	if err != nil || isBlocked {
+		return false, err
+	}
+
+	var private bool
+	err = db.DB.QueryRow("SELECT private FROM users WHERE user_id=$1", ownerId).Scan(&private)
+	if err != nil {
+		return false, err
+	}
+	if !private {
+		return true, nil
+	}
+
+	var following bool
+	err = db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM follower WHERE user_id=$1 AND follower_id=$2 AND accepted=$3)", viewerId, ownerId, true).Scan(&following)
+	if err != nil {
+		return false, err
+	}
+	return following, nil
+}
+
+// checks whether viewerId is allowed to see a story, returns the story owner
+func canViewStory(viewerId, storyId int64) (bool, int64, error) {
+	var ownerId int64
+	var success bool
+	var audience string
+	err := db.DB.QueryRow("SELECT user_id,success,audience FROM stories WHERE story_id=$1", storyId).Scan(&ownerId, &success, &audience)
+	if err == sql.ErrNoRows {
+		return false, 0, nil
+	}
+	if err != nil {
+		return false, 0, err
+	}
+
+	if ownerId == viewerId {
+		return true, ownerId, nil
+	}
+	if !success {
+		return false, ownerId, nil
+	}
+
+	visible, err := canViewProfile(viewerId, ownerId)
+	if err != nil || !visible {
+		return false, ownerId, err
+	}
+
+	//close friends stories are shown only to the owner's list
+	if audience == models.StoryAudienceCloseFriends {
+		err = db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM close_friends WHERE user_id=$1 AND friend_id=$2)", ownerId, viewerId).Scan(&visible)
+		if err != nil {
+			return false, ownerId, err
+		}
+	}
+	return visible, ownerId, nil
+}
@@ -38,6 +38,11 @@ func main() {
 		log.Fatal("Error creating posts directory", err)
 	}
 
+	//create highlights directory if not exists
+	if err := os.MkdirAll("./highlights", os.ModePerm); err != nil {
+		log.Fatal("Error creating highlights directory", err)
+	}
+
 	cron.Run()
 
 	http.HandleFunc("/newUserInfo", handlers.NewUser)
@@ -63,15 +68,30 @@ func main() {
 	//to get all posts of users
 	http.HandleFunc("/getAllPosts", handlers.AllPosts)
 
+	//posts of accounts i follow
+	http.HandleFunc("/homeFeed", handlers.HomeFeed)
+
 	// handle function to like 		a post
 	http.HandleFunc("/likePost", handlers.LikePosts)
 
+	//remove my like from a post
+	http.HandleFunc("/unlikePost", handlers.UnlikePost)
+
+	//users who liked a post
+	http.HandleFunc("/postLikers", handlers.PostLikers)
+
 	//handle func to comment a post based on postid
 	http.HandleFunc("/commentPost", handlers.CommentPost)
 
 	//handle func to get all comments of a post based on postId
 	http.HandleFunc("/getAllComments", handlers.AllComments)
 
+	//handle func to get replies of a comment
+	http.HandleFunc("/commentReplies", handlers.CommentReplies)
+
+	//like or unlike a comment
+	http.HandleFunc("/likeComment", handlers.LikeComment)
+
 	//handle function to follow(me following other)
 	http.HandleFunc("/follow", handlers.FollowOthers)
 
@@ -84,6 +104,24 @@ func main() {
 	//response to follow requests
 	http.HandleFunc("/respondingRequest", handlers.RespondingFollowRequests)
 
+	//how i am related to another user
+	http.HandleFunc("/relationship", handlers.GetRelationship)
+
+	//people i may want to follow
+	http.HandleFunc("/followSuggestions", handlers.FollowSuggestions)
+
+	//stop suggesting a user
+	http.HandleFunc("/dismissSuggestion", handlers.DismissSuggestion)
+
+	//switch my account between private and public
+	http.HandleFunc("/accountPrivacy", handlers.SetAccountPrivacy)
+
+	//follow requests i sent that are still pending
+	http.HandleFunc("/sentFollowRequests", handlers.SentFollowRequests)
+
+	//withdraw a follow request i sent
+	http.HandleFunc("/cancelFollowRequest", handlers.CancelFollowRequest)
+
 	//handleFunc to remove follower
 	http.HandleFunc("/removeFollower", handlers.RemoveFollowers)
 
@@ -120,6 +158,12 @@ func main() {
 
 	http.HandleFunc("/turnonComments", handlers.TurnONComments)
 
+	//set who can comment on a post
+	http.HandleFunc("/commentAudience", handlers.SetCommentAudience)
+
+	//set default comment audience for new posts
+	http.HandleFunc("/defaultCommentAudience", handlers.SetDefaultCommentAudience)
+
 	//hide like count
 	http.HandleFunc("/hidelikeCount", handlers.HideLikeCount)
 
@@ -129,6 +173,30 @@ func main() {
 	//delete comment
 	http.HandleFunc("/deleteComment", handlers.DeleteComment)
 
+	//edit my comment within the edit window
+	http.HandleFunc("/editComment", handlers.EditComment)
+
+	//earlier versions of an edited comment
+	http.HandleFunc("/commentHistory", handlers.CommentHistory)
+
+	//pin a comment on my post
+	http.HandleFunc("/pinComment", handlers.PinComment)
+
+	//unpin a comment on my post
+	http.HandleFunc("/unpinComment", handlers.UnpinComment)
+
+	//set keyword filter for comments on my posts
+	http.HandleFunc("/setCommentFilter", handlers.SetCommentFilter)
+
+	//get keyword filter for comments
+	http.HandleFunc("/commentFilter", handlers.GetCommentFilter)
+
+	//hidden comments waiting for review
+	http.HandleFunc("/hiddenComments", handlers.HiddenComments)
+
+	//approve or delete a hidden comment
+	http.HandleFunc("/reviewHiddenComment", handlers.ReviewHiddenComment)
+
 	//api for searching users
 
 	http.HandleFunc("/searchAccounts", handlers.SearchAccounts)
@@ -162,9 +230,96 @@ func main() {
 	//get active stories for a user
 	http.HandleFunc("/getActiveStories", handlers.AllActiveStories)
 
-	//updates story seen status
+	//records a story view, GET with post_id keeps returning the upload status for older clients
 	http.HandleFunc("/updateStorySeenStatus", handlers.UpdateStorySeenStatus)
 
+	//share an existing post to story
+	http.HandleFunc("/sharePostToStory", handlers.SharePostToStory)
+
+	//list viewers of a story
+	http.HandleFunc("/storyViewers", handlers.StoryViewers)
+
+	//react to a story
+	http.HandleFunc("/reactStory", handlers.ReactToStory)
+
+	//reply to a story
+	http.HandleFunc("/replyStory", handlers.ReplyToStory)
+
+	//reactions and replies of a story
+	http.HandleFunc("/storyResponses", handlers.StoryResponses)
+
+	//add a poll, question or slider sticker to a story
+	http.HandleFunc("/addStorySticker", handlers.AddStorySticker)
+
+	//vote or answer a story sticker
+	http.HandleFunc("/respondSticker", handlers.RespondToSticker)
+
+	//results of a story sticker
+	http.HandleFunc("/stickerResults", handlers.StickerResults)
+
+	//reshare a story the user is mentioned in
+	http.HandleFunc("/reshareStory", handlers.ReshareStory)
+
+	//notifications of a user
+	http.HandleFunc("/notifications", handlers.GetNotifications)
+
+	//add a user to close friends
+	http.HandleFunc("/addCloseFriend", handlers.AddCloseFriend)
+
+	//remove a user from close friends
+	http.HandleFunc("/removeCloseFriend", handlers.RemoveCloseFriend)
+
+	//list close friends
+	http.HandleFunc("/closeFriends", handlers.GetCloseFriends)
+
+	//block a user
+	http.HandleFunc("/blockUser", handlers.BlockUser)
+
+	//unblock a user
+	http.HandleFunc("/unblockUser", handlers.UnblockUser)
+
+	//list blocked users
+	http.HandleFunc("/blockedUsers", handlers.BlockedUsers)
+
+	//restrict a user
+	http.HandleFunc("/restrictUser", handlers.RestrictUser)
+
+	//unrestrict a user
+	http.HandleFunc("/unrestrictUser", handlers.UnrestrictUser)
+
+	//list restricted users
+	http.HandleFunc("/restrictedUsers", handlers.RestrictedUsers)
+
+	//tags by restricted users waiting for approval
+	http.HandleFunc("/pendingTags", handlers.PendingTags)
+
+	//approve or remove a pending tag
+	http.HandleFunc("/respondTag", handlers.RespondTag)
+
+	//mute posts or stories of a user
+	http.HandleFunc("/muteUser", handlers.MuteUser)
+
+	//list muted users
+	http.HandleFunc("/mutedUsers", handlers.MutedUsers)
+
+	//create a story highlight
+	http.HandleFunc("/createHighlight", handlers.CreateHighlight)
+
+	//edit title or stories of a highlight
+	http.HandleFunc("/editHighlight", handlers.EditHighlight)
+
+	//reorder stories of a highlight
+	http.HandleFunc("/reorderHighlight", handlers.ReorderHighlight)
+
+	//delete a highlight
+	http.HandleFunc("/deleteHighlight", handlers.DeleteHighlight)
+
+	//upload cover image of a highlight
+	http.HandleFunc("/uploadHighlightCover", handlers.UploadHighlightCover)
+
+	//serve highlight covers
+	http.HandleFunc("/download/highlights/", handlers.DownloadHighlightCover)
+
 	http.ListenAndServe(":3000", nil)
 
 }
@@ -13,6 +13,7 @@ type NewUser struct {
 }
 
 type UserName struct {
+	UserID   int64  `json:"user_id"`
 	UserName string `json:"user_name"`
 }
 
@@ -30,6 +31,7 @@ type InsertPost struct {
 	Location        *string  `json:"location"`
 	HideLikeCount   *bool    `json:"hide_like_count"`
 	TurnOffComments *bool    `json:"turnoff_comments"`
+	CommentAudience string   `json:"comment_audience"`
 }
 
 // returned postid by postMedia api
@@ -42,20 +44,23 @@ type UserID struct {
 
 // struct to get user posts and post by postId
 type UsersPost struct {
-	UserID            int64    `json:"user_iD"`
-	UserName          string   `json:"user_name"`
-	UserProfilePicURL string   `json:"user_profile_picUrl"`
-	PostId            int64    `json:"post_id"`
-	PostURL           []string `json:"postURL"`
-	FileType          string   `json:"file_type"`
-	AttachedLocation  string   `json:"attached_location"`
-	LikeStatus        bool     `json:"like_status"`
-	Likes             int64    `json:"likes"`
-	PostCaption       string   `json:"caption"`
-	HideLikeCount     bool     `json:"hide_like_count"`
-	TurnOffComments   bool     `json:"turnoff_comments"`
-	SavedStatus       bool     `json:"saved_post"`
-	PostedOn          string   `json:"posted_on"`
+	UserID            int64            `json:"user_iD"`
+	UserName          string           `json:"user_name"`
+	UserProfilePicURL string           `json:"user_profile_picUrl"`
+	PostId            int64            `json:"post_id"`
+	PostURL           []string         `json:"postURL"`
+	FileType          string           `json:"file_type"`
+	AttachedLocation  string           `json:"attached_location"`
+	LikeStatus        bool             `json:"like_status"`
+	Reaction          string           `json:"reaction,omitempty"`
+	Likes             *int64           `json:"likes,omitempty"`
+	Reactions         map[string]int64 `json:"reactions,omitempty"`
+	PostCaption       string           `json:"caption"`
+	HideLikeCount     bool             `json:"hide_like_count"`
+	TurnOffComments   bool             `json:"turnoff_comments"`
+	CommentAudience   string           `json:"comment_audience"`
+	SavedStatus       bool             `json:"saved_post"`
+	PostedOn          string           `json:"posted_on"`
 }
 
 // posting like to a post
@@ -64,16 +69,28 @@ type LikePost struct {
 	UserID int64 `json:"user_id"`
 }
 
+// heart reaction stored for a plain like
+const PostReactionLike = "❤️"
+
+// to react to a post, reaction defaults to a like
+type PostReaction struct {
+	PostId   int64  `json:"post_id"`
+	UserID   int64  `json:"user_id"`
+	Reaction string `json:"reaction"`
+}
+
 // to get count of likes on a post
 type TotalLikes struct {
-	TotalLikes int64 `json:"total_likes"`
+	TotalLikes *int64           `json:"total_likes,omitempty"`
+	Reactions  map[string]int64 `json:"reactions,omitempty"`
 }
 
 // to post a comment
 type CommentBody struct {
-	PostId      int64  `json:"post_id"`
-	UserID      int64  `json:"user_id"`
-	CommentBody string `json:"comment_body"`
+	PostId          int64  `json:"post_id"`
+	UserID          int64  `json:"user_id"`
+	CommentBody     string `json:"comment_body"`
+	ParentCommentId int64  `json:"parent_comment_id"`
 }
 
 // comment id returned after succefull comment insertion
@@ -87,8 +104,93 @@ type CommentsOfPost struct {
 	CommentorUserName   string `json:"commentor_user_name"`
 	CommentorDisplayPic string `json:"commentor_display_pic"`
 	PostId              int64  `json:"post_id"`
+	ParentCommentId     int64  `json:"parent_comment_id,omitempty"`
 	CommentBody         string `json:"comment_body"`
 	CommentedOn         string `json:"commented_on"`
+	ReplyCount          int64  `json:"reply_count"`
+	LikeCount           int64  `json:"like_count"`
+	LikeStatus          bool   `json:"like_status"`
+	Hidden              bool   `json:"hidden"`
+	Edited              bool   `json:"edited"`
+	Pinned              bool   `json:"pinned"`
+}
+
+// pin or unpin a comment on my post
+type PinComment struct {
+	UserID    int64 `json:"user_id"`
+	CommentId int64 `json:"comment_id"`
+}
+
+// edit the body of a comment
+type EditComment struct {
+	UserID      int64  `json:"user_id"`
+	CommentId   int64  `json:"comment_id"`
+	CommentBody string `json:"comment_body"`
+}
+
+// earlier body of an edited comment
+type CommentEdit struct {
+	CommentBody string `json:"comment_body"`
+	EditedOn    string `json:"edited_on"`
+}
+
+// edit history of a comment, oldest body first
+type CommentHistory struct {
+	CommentId   int64         `json:"comment_id"`
+	CommentBody string        `json:"comment_body"`
+	EditedOn    string        `json:"edited_on,omitempty"`
+	Edits       []CommentEdit `json:"edits"`
+}
+
+// to like or unlike a comment
+type LikeComment struct {
+	UserID    int64 `json:"user_id"`
+	CommentId int64 `json:"comment_id"`
+}
+
+type CommentLikeStatus struct {
+	CommentId  int64 `json:"comment_id"`
+	LikeStatus bool  `json:"like_status"`
+	TotalLikes int64 `json:"total_likes"`
+}
+
+// to get comments of a post or replies of a comment
+type CommentsRequest struct {
+	UserID    int64 `json:"user_id"`
+	PostId    int64 `json:"post_id"`
+	CommentId int64 `json:"comment_id"`
+	Page      int   `json:"page"`
+	Limit     int   `json:"limit"`
+}
+
+// who can comment on a post
+const (
+	CommentAudienceEveryone  = "everyone"
+	CommentAudienceFollowing = "following" // people the owner follows
+	CommentAudienceFollowers = "followers" // followers of the owner
+	CommentAudienceMutual    = "mutual"    // followers the owner follows back
+	CommentAudienceOff       = "off"
+)
+
+// to set comment audience of a post, the account default is set through /defaultCommentAudience which ignores post_id
+type CommentAudience struct {
+	UserID   int64  `json:"user_id"`
+	PostId   int64  `json:"post_id"`
+	Audience string `json:"audience"`
+}
+
+// comment filter of a user
+type CommentFilter struct {
+	UserID        int64    `json:"user_id"`
+	HideOffensive *bool    `json:"hide_offensive"`
+	Keywords      []string `json:"keywords"`
+}
+
+// to approve or delete a hidden comment
+type ReviewComment struct {
+	UserID    int64 `json:"user_id"`
+	CommentId int64 `json:"comment_id"`
+	Approve   bool  `json:"approve"`
 }
 
 // to delete a comment
@@ -127,14 +229,28 @@ type ProfileUpdate struct {
 
 // to give profile info response
 type Profile struct {
-	UserID         int64  `json:"user_id"`
-	UserName       string `json:"user_name"`
-	PrivateAccount bool   `json:"private_account"`
-	PostCount      int64  `json:"post_count"`
-	FollowerCount  int64  `json:"follower_count"`
-	FollowingCount int64  `json:"following_count"`
-	Bio            string `json:"bio"`
-	ProfilePic     string `json:"profile_picURL"`
+	UserID         int64         `json:"user_id"`
+	UserName       string        `json:"user_name"`
+	PrivateAccount bool          `json:"private_account"`
+	PostCount      int64         `json:"post_count"`
+	FollowerCount  int64         `json:"follower_count"`
+	FollowingCount int64         `json:"following_count"`
+	Bio            string        `json:"bio"`
+	ProfilePic     string        `json:"profile_picURL"`
+	Highlights     []Highlight   `json:"highlights"`
+	Relationship   *Relationship `json:"relationship,omitempty"`
+}
+
+// relationship of the viewer with a user, mutual followers are accounts the viewer follows who follow the user
+type Relationship struct {
+	UserID          int64      `json:"user_id"`
+	Following       bool       `json:"following"`
+	FollowedBy      bool       `json:"followed_by"`
+	Requested       bool       `json:"requested"`
+	Blocked         bool       `json:"blocked"`
+	Muted           bool       `json:"muted"`
+	MutualFollowers []Accounts `json:"mutual_followers"`
+	MutualCount     int64      `json:"mutual_count"`
 }
 
 // to get all follower and following
@@ -154,6 +270,25 @@ type Accounts struct {
 	ProfilePic string `json:"profile_pic"`
 }
 
+// paginated list of users who liked a post
+type PostLikersRequest struct {
+	UserID   int64  `json:"user_id"`
+	PostId   int64  `json:"post_id"`
+	Reaction string `json:"reaction"`
+	Page     int    `json:"page"`
+	Limit    int    `json:"limit"`
+}
+
+// user who liked a post, following is whether the viewer follows them
+type Liker struct {
+	UserID     int64  `json:"user_id"`
+	UserName   string `json:"user_name"`
+	Name       string `json:"name"`
+	ProfilePic string `json:"profile_pic"`
+	Reaction   string `json:"reaction"`
+	Following  bool   `json:"following"`
+}
+
 // saved posts response
 type SavedPosts struct {
 	PostId      int64  `json:"post_id"`
@@ -169,6 +304,51 @@ type FollowRequest struct {
 	CreatedOn  string `json:"request_created_on"`
 	Accepted   bool   `json:"accepted"`
 }
+
+// paginated follow suggestions of a user
+type SuggestionsRequest struct {
+	UserID int64 `json:"user_id"`
+	Page   int   `json:"page"`
+	Limit  int   `json:"limit"`
+}
+
+// suggested account with why it was suggested
+type FollowSuggestion struct {
+	UserID         int64  `json:"user_id"`
+	UserName       string `json:"user_name"`
+	Name           string `json:"name"`
+	ProfilePic     string `json:"profile_pic"`
+	MutualCount    int64  `json:"mutual_count"`
+	FollowsYou     bool   `json:"follows_you"`
+	TaggedTogether int64  `json:"tagged_together"`
+}
+
+// dismiss a follow suggestion
+type DismissSuggestion struct {
+	UserID      int64 `json:"user_id"`
+	SuggestedId int64 `json:"suggested_id"`
+}
+
+// switch an account between private and public
+type PrivacySetting struct {
+	UserID  int64 `json:"user_id"`
+	Private *bool `json:"private"`
+}
+
+// privacy of an account after a switch, large accounts accept pending requests in the background
+type PrivacyStatus struct {
+	Private               bool  `json:"private"`
+	AcceptedRequests      int64 `json:"accepted_requests"`
+	AcceptingInBackground bool  `json:"accepting_in_background"`
+}
+
+// follow request sent by a user that is still pending
+type SentFollowRequest struct {
+	UserID      int64  `json:"user_id"`
+	UserName    string `json:"user_name"`
+	ProfilePic  string `json:"profile_pic"`
+	RequestedOn string `json:"requested_on"`
+}
 type FollowAcceptance struct {
 	AcceptorUserID int64 `json:"acceptor_user_id"`
 	RequestorId    int64 `json:"requestor_user_id"`
@@ -191,12 +371,30 @@ type HashtagSearchResult struct {
 type Newhashtag struct {
 	NewHashId int64 `json:"new_hash_tag_id"`
 }
+
+// audience of a story
+const (
+	StoryAudienceEveryone     = "everyone"
+	StoryAudienceCloseFriends = "close_friends"
+)
+
 type StoryInfo struct {
-	UserID    int64     `json:"user_id"`
-	TaggedIds [][]int64 `json:"tagged_ids"`
+	UserID    int64        `json:"user_id"`
+	TaggedIds [][]int64    `json:"tagged_ids"`
+	Tags      [][]StoryTag `json:"tags"`
+	Audience  string       `json:"audience"`
+}
+
+// tagged user with normalized position and rotation on the story
+type StoryTag struct {
+	UserID   int64   `json:"user_id"`
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+	Rotation float64 `json:"rotation"`
 }
 type StoryMedia struct {
 	StoryId int64 `json:"story_id"`
+	UserID  int64 `json:"user_id"`
 }
 
 type ReturnedStoryId struct {
@@ -210,25 +408,109 @@ type UploadStory struct {
 }
 
 type GetStory struct {
-	StoryId   int64   `json:"story_id"`
-	StoryURL  string  `json:"storyurl"`
-	PostedOn  string  `json:"posted_on"`
-	Success   bool    `json:"upload_status"`
-	TaggedIds []int64 `json:"tagged_userids"`
-	FileType  string  `json:"file_type"`
+	StoryId    int64          `json:"story_id"`
+	StoryURL   string         `json:"storyurl"`
+	PostedOn   string         `json:"posted_on"`
+	Success    bool           `json:"upload_status"`
+	TaggedIds  []int64        `json:"tagged_userids"`
+	Tags       []StoryTag     `json:"tags"`
+	FileType   string         `json:"file_type"`
+	Audience   string         `json:"audience"`
+	SharedPost *SharedPost    `json:"shared_post,omitempty"`
+	Reshared   int64          `json:"reshared_from,omitempty"`
+	Stickers   []StorySticker `json:"stickers"`
 }
 type PostAsStory struct {
 	UserID    int64   `json:"user_id"`
+	PostId    int64   `json:"post_id"`
 	TaggedIds []int64 `json:"tagged_ids"`
 	StoryURL  string  `json:"storyURL"`
+	Audience  string  `json:"audience"`
+}
+
+// attribution of a post shared as story
+type SharedPost struct {
+	PostId   int64  `json:"post_id"`
+	UserID   int64  `json:"user_id"`
+	UserName string `json:"user_name"`
+	PostURL  string `json:"postURL"`
 }
 
+// story ring of an author in the story tray
 type ActiveStories struct {
-	User_id        int64   `json:"user_id"`
-	User_name      string  `json:"user_name"`
-	Profile_picURL string  `json:"profile_pic_url"`
-	Story_id       []int64 `json:"story_ids"`
-	Seen_status    bool    `json:"story_seen_status"`
+	User_id        int64       `json:"user_id"`
+	User_name      string      `json:"user_name"`
+	Profile_picURL string      `json:"profile_pic_url"`
+	Story_id       []int64     `json:"story_ids"`
+	Seen_status    bool        `json:"story_seen_status"`
+	Stories        []RingStory `json:"stories"`
+	LatestPostedOn string      `json:"latest_posted_on"`
+}
+
+type RingStory struct {
+	StoryId  int64  `json:"story_id"`
+	PostedOn string `json:"posted_on"`
+	Seen     bool   `json:"seen"`
+}
+
+// block or unblock a user
+type Block struct {
+	UserID    int64 `json:"user_id"`
+	BlockedId int64 `json:"blocked_id"`
+}
+
+// restrict or unrestrict a user
+type Restrict struct {
+	UserID       int64 `json:"user_id"`
+	RestrictedId int64 `json:"restricted_id"`
+}
+
+// tag of a user by a restricted account waiting for approval
+type PendingTag struct {
+	PostId         int64  `json:"post_id,omitempty"`
+	StoryId        int64  `json:"story_id,omitempty"`
+	TaggedById     int64  `json:"tagged_by_id"`
+	TaggedByName   string `json:"tagged_by_user_name"`
+	TaggedByPicURL string `json:"tagged_by_profile_pic"`
+}
+
+// approve or remove a pending tag on a post or a story
+type RespondTag struct {
+	UserID  int64 `json:"user_id"`
+	PostId  int64 `json:"post_id"`
+	StoryId int64 `json:"story_id"`
+	Approve bool  `json:"approve"`
+}
+
+// mute posts, stories or both of an account, both false unmutes
+type Mute struct {
+	UserID      int64 `json:"user_id"`
+	MutedId     int64 `json:"muted_id"`
+	MutePosts   *bool `json:"mute_posts"`
+	MuteStories *bool `json:"mute_stories"`
+}
+
+// muted account with what is muted
+type MutedAccount struct {
+	UserID      int64  `json:"user_id"`
+	UserName    string `json:"user_name"`
+	Name        string `json:"name"`
+	ProfilePic  string `json:"profile_pic"`
+	MutePosts   bool   `json:"mute_posts"`
+	MuteStories bool   `json:"mute_stories"`
+}
+
+// paginated home feed of a user
+type FeedRequest struct {
+	UserID int64 `json:"user_id"`
+	Page   int   `json:"page"`
+	Limit  int   `json:"limit"`
+}
+
+// to add or remove a close friend
+type CloseFriend struct {
+	UserID   int64 `json:"user_id"`
+	FriendId int64 `json:"friend_id"`
 }
 
 type UpdateStorySeenStatus struct {
@@ -236,6 +518,178 @@ type UpdateStorySeenStatus struct {
 	StoryId int64 `json:"story_id"`
 }
 
+type StorySeen struct {
+	StoryId    int64 `json:"story_id"`
+	SeenStatus bool  `json:"seen_status"`
+}
+
+// to list viewers of a story page by page
+type StoryViewersRequest struct {
+	UserID  int64 `json:"user_id"`
+	StoryId int64 `json:"story_id"`
+	Page    int   `json:"page"`
+	Limit   int   `json:"limit"`
+}
+
+type StoryViewer struct {
+	UserID     int64  `json:"user_id"`
+	UserName   string `json:"user_name"`
+	ProfilePic string `json:"profile_pic"`
+	SeenOn     string `json:"seen_on"`
+}
+
+type StoryViewers struct {
+	StoryId   int64         `json:"story_id"`
+	ViewCount int64         `json:"view_count"`
+	Viewers   []StoryViewer `json:"viewers"`
+}
+
+// to create or edit a story highlight
+type HighlightInfo struct {
+	UserID      int64   `json:"user_id"`
+	HighlightId int64   `json:"highlight_id"`
+	Title       *string `json:"title"`
+	StoryIds    []int64 `json:"story_ids"`
+}
+
+type ReturnedHighlightId struct {
+	HighlightId int64 `json:"highlight_id"`
+}
+
+// highlight served on the profile
+type Highlight struct {
+	HighlightId int64   `json:"highlight_id"`
+	Title       string  `json:"title"`
+	CoverURL    string  `json:"cover_url"`
+	StoryIds    []int64 `json:"story_ids"`
+	CreatedOn   string  `json:"created_on"`
+}
+
+// profile of user_id as seen by viewer_id
+type ProfileView struct {
+	UserId   int64 `json:"user_id"`
+	ViewerId int64 `json:"viewer_id"`
+}
+
+// emojis allowed as story reactions
+var StoryReactionEmojis = []string{"😂", "😮", "😍", "😢", "👏", "🔥", "🎉", "💯"}
+
+// to react to a story
+type StoryReaction struct {
+	UserID  int64  `json:"user_id"`
+	StoryId int64  `json:"story_id"`
+	Emoji   string `json:"emoji"`
+}
+
+// to reply to a story
+type StoryReply struct {
+	UserID    int64  `json:"user_id"`
+	StoryId   int64  `json:"story_id"`
+	ReplyBody string `json:"reply_body"`
+}
+
+type ReturnedReplyId struct {
+	ReplyId int64 `json:"reply_id"`
+}
+
+type StoryReactionEntry struct {
+	UserID     int64  `json:"user_id"`
+	UserName   string `json:"user_name"`
+	ProfilePic string `json:"profile_pic"`
+	Emoji      string `json:"emoji"`
+	ReactedOn  string `json:"reacted_on"`
+}
+
+type StoryReplyEntry struct {
+	ReplyId    int64  `json:"reply_id"`
+	UserID     int64  `json:"user_id"`
+	UserName   string `json:"user_name"`
+	ProfilePic string `json:"profile_pic"`
+	ReplyBody  string `json:"reply_body"`
+	RepliedOn  string `json:"replied_on"`
+}
+
+// reactions and replies of a story served to its author
+type StoryResponses struct {
+	StoryId   int64                `json:"story_id"`
+	Reactions []StoryReactionEntry `json:"reactions"`
+	Replies   []StoryReplyEntry    `json:"replies"`
+}
+
+// types of interactive story stickers
+const (
+	StickerPoll     = "poll"
+	StickerQuestion = "question"
+	StickerSlider   = "slider"
+)
+
+// to add a sticker to a story
+type StoryStickerInfo struct {
+	UserID      int64    `json:"user_id"`
+	StoryId     int64    `json:"story_id"`
+	StickerType string   `json:"sticker_type"`
+	Prompt      string   `json:"prompt"`
+	Options     []string `json:"options"`
+	Emoji       string   `json:"emoji"`
+}
+
+type ReturnedStickerId struct {
+	StickerId int64 `json:"sticker_id"`
+}
+
+// sticker served with the story
+type StorySticker struct {
+	StickerId   int64    `json:"sticker_id"`
+	StickerType string   `json:"sticker_type"`
+	Prompt      string   `json:"prompt"`
+	Options     []string `json:"options,omitempty"`
+	Emoji       string   `json:"emoji,omitempty"`
+}
+
+// vote, answer or slider value of a viewer
+type StickerResponse struct {
+	UserID      int64    `json:"user_id"`
+	StickerId   int64    `json:"sticker_id"`
+	OptionIndex *int     `json:"option_index"`
+	Answer      *string  `json:"answer"`
+	SliderValue *float64 `json:"slider_value"`
+}
+
+type StickerAnswer struct {
+	UserID     int64  `json:"user_id"`
+	UserName   string `json:"user_name"`
+	Answer     string `json:"answer"`
+	AnsweredOn string `json:"answered_on"`
+}
+
+// aggregated sticker results served to the story author
+type StickerResults struct {
+	StickerId      int64           `json:"sticker_id"`
+	StickerType    string          `json:"sticker_type"`
+	Prompt         string          `json:"prompt"`
+	TotalResponses int64           `json:"total_responses"`
+	OptionVotes    []int64         `json:"option_votes,omitempty"`
+	SliderAverage  *float64        `json:"slider_average,omitempty"`
+	Answers        []StickerAnswer `json:"answers,omitempty"`
+}
+
+// types of notifications
+const (
+	NotificationStoryMention = "story_mention"
+)
+
+type Notification struct {
+	NotificationId  int64  `json:"notification_id"`
+	Type            string `json:"notification_type"`
+	ActorId         int64  `json:"actor_id"`
+	ActorUserName   string `json:"actor_user_name"`
+	ActorProfilePic string `json:"actor_profile_pic"`
+	StoryId         int64  `json:"story_id,omitempty"`
+	CanReshare      bool   `json:"can_reshare"`
+	Seen            bool   `json:"seen"`
+	CreatedOn       string `json:"created_on"`
+}
+
 // func to get the file extensions(used while serving files)
 func GetExtension(extension string) string {
 	switch extension {
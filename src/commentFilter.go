@@ -0,0 +1,62 @@
+package src
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// built-in list of offensive words hidden when hide_offensive is on, kept to terms that are abusive in any context
+var offensiveWords = map[string]bool{
+	"retard":   true,
+	"bitch":    true,
+	"bastard":  true,
+	"whore":    true,
+	"slut":     true,
+	"asshole":  true,
+	"dickhead": true,
+	"kys":      true,
+}
+
+// checks if a comment should be hidden by the post owner's filter
+func MatchesCommentFilter(body string, hideOffensive bool, keywords []string) bool {
+	lower := strings.ToLower(body)
+
+	if hideOffensive {
+		words := strings.FieldsFunc(lower, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+		})
+		for _, word := range words {
+			if offensiveWords[word] {
+				return true
+			}
+		}
+	}
+
+	//custom keywords and emoji can appear anywhere in the comment
+	for _, keyword := range keywords {
+		if keyword != "" && strings.Contains(lower, strings.ToLower(keyword)) {
+			return true
+		}
+	}
+	return false
+}
+
+// trims custom filter keywords and validates their count and length
+func ValidateFilterKeywords(keywords []string) ([]string, error) {
+	if len(keywords) > 100 {
+		return nil, errors.New("Only 100 keywords allowed in the comment filter")
+	}
+	cleaned := []string{}
+	for _, keyword := range keywords {
+		keyword = strings.TrimSpace(keyword)
+		if keyword == "" {
+			continue
+		}
+		if len(keyword) > 50 {
+			return nil, errors.New("Keyword should not exceed 50 characters")
+		}
+		cleaned = append(cleaned, keyword)
+	}
+	return cleaned, nil
+}
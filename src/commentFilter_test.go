@@ -0,0 +1,46 @@
+package src
+
+import "testing"
+
+func TestMatchesCommentFilter(t *testing.T) {
+	tests := []struct {
+		body          string
+		hideOffensive bool
+		keywords      []string
+		hidden        bool
+	}{
+		{"what a bastard", true, nil, true},
+		{"What a BASTARD!", true, nil, true},
+		{"what a bastard", false, nil, false},
+		//everyday words are not part of the built-in list
+		{"this cake is so fat and the trash talk was dumb", true, nil, false},
+		{"bastardized recipe", true, nil, false},
+		{"see you at the Spoiler party", false, []string{"spoiler"}, true},
+		{"🍍 on pizza", false, []string{"🍍"}, true},
+		{"nothing to hide", true, []string{"spoiler"}, false},
+	}
+
+	for _, test := range tests {
+		if hidden := MatchesCommentFilter(test.body, test.hideOffensive, test.keywords); hidden != test.hidden {
+			t.Errorf("MatchesCommentFilter(%q, %v, %q) = %v, expected %v", test.body, test.hideOffensive, test.keywords, hidden, test.hidden)
+		}
+	}
+}
+
+func TestValidateFilterKeywords(t *testing.T) {
+	keywords, err := ValidateFilterKeywords([]string{" spoiler ", "", "  "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keywords) != 1 || keywords[0] != "spoiler" {
+		t.Errorf("expected [spoiler], got %q", keywords)
+	}
+
+	long := make([]byte, 51)
+	for i := range long {
+		long[i] = 'a'
+	}
+	if _, err = ValidateFilterKeywords([]string{string(long)}); err == nil {
+		t.Error("expected an error for a keyword over 50 characters")
+	}
+}
@@ -0,0 +1,57 @@
+package src
+
+import "backend/db"
+
+// pending requests accepted per statement when an account goes public
+const acceptBatchSize = 1000
+
+// accepts pending follow requests of a public account in batches, stops if the account turns private again
+func AcceptPendingRequests(userId int64) (int64, error) {
+	var total int64
+	for {
+		result, err := db.DB.Exec(`UPDATE follower SET accepted=$2 WHERE (user_id,follower_id) IN (
+			SELECT f.user_id,f.follower_id FROM follower f JOIN users u ON u.user_id=f.follower_id
+			WHERE f.follower_id=$1 AND f.accepted=$3 AND u.private=$3 LIMIT $4)`, userId, true, false, acceptBatchSize)
+		if err != nil {
+			return total, err
+		}
+		accepted, err := result.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += accepted
+		if accepted < acceptBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// accepts requests left pending on public accounts, e.g. by an interrupted background job
+func AcceptStrandedRequests() (int64, error) {
+	row, err := db.DB.Query(`SELECT DISTINCT f.follower_id FROM follower f JOIN users u ON u.user_id=f.follower_id
+		WHERE f.accepted=$1 AND u.private=$1`, false)
+	if err != nil {
+		return 0, err
+	}
+
+	var userIds []int64
+	for row.Next() {
+		var userId int64
+		if err = row.Scan(&userId); err != nil {
+			row.Close()
+			return 0, err
+		}
+		userIds = append(userIds, userId)
+	}
+	row.Close()
+
+	var total int64
+	for _, userId := range userIds {
+		accepted, err := AcceptPendingRequests(userId)
+		total += accepted
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,41 @@
+package src
+
+import (
+	"backend/models"
+	"os"
+	"strings"
+)
+
+// post reactions used when POST_REACTIONS is not set in .env
+var defaultPostReactions = []string{models.PostReactionLike, "😂", "😮", "😢", "😡", "👍"}
+
+// returns the allowed post reactions, configured as a comma separated POST_REACTIONS list
+func PostReactions() []string {
+	var reactions []string
+	for _, reaction := range strings.Split(os.Getenv("POST_REACTIONS"), ",") {
+		reaction = strings.TrimSpace(reaction)
+		if reaction != "" {
+			reactions = append(reactions, reaction)
+		}
+	}
+	if len(reactions) == 0 {
+		return defaultPostReactions
+	}
+	return reactions
+}
+
+// strips emoji variation selectors so "❤" and "❤️" are the same reaction
+func normalizeReaction(reaction string) string {
+	return strings.ReplaceAll(strings.TrimSpace(reaction), "\uFE0F", "")
+}
+
+// matches a reaction against the allowed set and returns the form it is stored in
+func PostReaction(reaction string) (string, bool) {
+	normalized := normalizeReaction(reaction)
+	for _, emoji := range PostReactions() {
+		if normalizeReaction(emoji) == normalized {
+			return emoji, true
+		}
+	}
+	return "", false
+}
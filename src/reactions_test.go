@@ -0,0 +1,35 @@
+package src
+
+import (
+	"backend/models"
+	"testing"
+)
+
+func TestPostReactionNormalizesVariationSelector(t *testing.T) {
+	t.Setenv("POST_REACTIONS", "")
+
+	for _, input := range []string{"❤", "❤️", " ❤️ "} {
+		reaction, ok := PostReaction(input)
+		if !ok || reaction != models.PostReactionLike {
+			t.Errorf("PostReaction(%q) = %q, %v, expected %q", input, reaction, ok, models.PostReactionLike)
+		}
+	}
+	if _, ok := PostReaction("🍍"); ok {
+		t.Error("expected 🍍 to be rejected by the default reactions")
+	}
+}
+
+func TestPostReactionsFromConfig(t *testing.T) {
+	t.Setenv("POST_REACTIONS", "❤️, 🍍 ,,🔥")
+
+	reactions := PostReactions()
+	if len(reactions) != 3 || reactions[1] != "🍍" {
+		t.Fatalf("expected configured reactions, got %q", reactions)
+	}
+	if _, ok := PostReaction("🍍"); !ok {
+		t.Error("expected 🍍 to be allowed by the configuration")
+	}
+	if _, ok := PostReaction("😂"); ok {
+		t.Error("expected 😂 to be rejected when not configured")
+	}
+}
@@ -0,0 +1,93 @@
+package src
+
+import (
+	"backend/db"
+	"log"
+)
+
+// suggestions kept per user after each computation
+const maxSuggestions = 100
+
+// follow suggestions of a user from the social graph: friends of friends, followers not followed back and
+// people tagged together with the user, leaving out accounts already followed, blocked or dismissed
+const computeSuggestions = `WITH candidates AS (
+	SELECT f2.follower_id AS suggested_id,COUNT(DISTINCT f1.follower_id) AS mutual,0 AS follows_you,0 AS tagged
+	FROM follower f1 JOIN follower f2 ON f2.user_id=f1.follower_id AND f2.accepted=$2
+	WHERE f1.user_id=$1 AND f1.accepted=$2 GROUP BY f2.follower_id
+	UNION ALL
+	SELECT user_id,0,1,0 FROM follower WHERE follower_id=$1 AND accepted=$2
+	UNION ALL
+	SELECT t2.tagged_ids,0,0,COUNT(DISTINCT t1.post_id)
+	FROM tagged_users t1 JOIN tagged_users t2 ON t2.post_id=t1.post_id AND t2.tagged_ids<>t1.tagged_ids AND t2.approved=$2
+	WHERE t1.tagged_ids=$1 AND t1.approved=$2 GROUP BY t2.tagged_ids
+)
+INSERT INTO follow_suggestions(user_id,suggested_id,mutual_count,follows_you,tagged_together,score)
+SELECT $1,c.suggested_id,SUM(c.mutual),MAX(c.follows_you)=1,SUM(c.tagged),SUM(c.mutual)*2+MAX(c.follows_you)*3+SUM(c.tagged)
+FROM candidates c
+WHERE c.suggested_id<>$1
+AND NOT EXISTS(SELECT 1 FROM follower f WHERE f.user_id=$1 AND f.follower_id=c.suggested_id)
+AND NOT EXISTS(SELECT 1 FROM blocks b WHERE (b.user_id=$1 AND b.blocked_id=c.suggested_id) OR (b.user_id=c.suggested_id AND b.blocked_id=$1))
+AND NOT EXISTS(SELECT 1 FROM dismissed_suggestions d WHERE d.user_id=$1 AND d.suggested_id=c.suggested_id)
+GROUP BY c.suggested_id
+ORDER BY 6 DESC,c.suggested_id LIMIT $3`
+
+// replaces the stored follow suggestions of a user
+func ComputeSuggestions(userId int64) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("DELETE FROM follow_suggestions WHERE user_id=$1", userId)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(computeSuggestions, userId, true, maxSuggestions)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("INSERT INTO suggestions_computed(user_id) VALUES($1) ON CONFLICT (user_id) DO UPDATE SET computed_on=current_timestamp", userId)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// users never computed, whose follows or whose followees' follows changed since, or computed over a day ago
+const staleSuggestions = `SELECT u.user_id FROM users u LEFT JOIN suggestions_computed c ON c.user_id=u.user_id
+	WHERE c.user_id IS NULL OR c.computed_on<current_timestamp-interval '1 day'
+	OR EXISTS(SELECT 1 FROM follower f WHERE (f.user_id=u.user_id OR f.follower_id=u.user_id) AND f.created_at>c.computed_on)
+	OR EXISTS(SELECT 1 FROM follower f1 JOIN follower f2 ON f2.user_id=f1.follower_id
+		WHERE f1.user_id=u.user_id AND f1.accepted=$1 AND f2.created_at>c.computed_on)`
+
+// recomputes follow suggestions of users whose graph changed, a failing user is logged and skipped
+func ComputeAllSuggestions() (int, error) {
+	row, err := db.DB.Query(staleSuggestions, true)
+	if err != nil {
+		return 0, err
+	}
+
+	var userIds []int64
+	for row.Next() {
+		var userId int64
+		if err = row.Scan(&userId); err != nil {
+			row.Close()
+			return 0, err
+		}
+		userIds = append(userIds, userId)
+	}
+	row.Close()
+
+	computed := 0
+	for _, userId := range userIds {
+		if err = ComputeSuggestions(userId); err != nil {
+			log.Println("Error computing follow suggestions of user", userId, err)
+			continue
+		}
+		computed++
+	}
+	return computed, nil
+}